@@ -0,0 +1,117 @@
+package evaluator
+
+import (
+	"fmt"
+	"monkey/object"
+	"sort"
+	"unicode/utf8"
+)
+
+var builtins = map[string]*object.Builtin{
+	"len": {
+		Name: "len",
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newBuiltinError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *object.String:
+				return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+			case *object.Array:
+				return &object.Integer{Value: int64(len(arg.Elements))}
+			case *object.Hash:
+				return &object.Integer{Value: int64(len(arg.Keys))}
+			default:
+				return newBuiltinError("argument to `len` not supported, got %s", args[0].Type())
+			}
+		},
+	},
+	"first": {
+		Name: "first",
+		Fn: func(args ...object.Object) object.Object {
+			array, err := arrayArgument("first", args)
+			if err != nil {
+				return err
+			}
+			if len(array.Elements) > 0 {
+				return array.Elements[0]
+			}
+			return NULL
+		},
+	},
+	"last": {
+		Name: "last",
+		Fn: func(args ...object.Object) object.Object {
+			array, err := arrayArgument("last", args)
+			if err != nil {
+				return err
+			}
+			if length := len(array.Elements); length > 0 {
+				return array.Elements[length-1]
+			}
+			return NULL
+		},
+	},
+	"rest": {
+		Name: "rest",
+		Fn: func(args ...object.Object) object.Object {
+			array, err := arrayArgument("rest", args)
+			if err != nil {
+				return err
+			}
+			if length := len(array.Elements); length > 0 {
+				newElements := make([]object.Object, length-1)
+				copy(newElements, array.Elements[1:length])
+				return &object.Array{Elements: newElements}
+			}
+			return NULL
+		},
+	},
+	"push": {
+		Name: "push",
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newBuiltinError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			array, err := arrayArgument("push", args[:1])
+			if err != nil {
+				return err
+			}
+
+			length := len(array.Elements)
+			newElements := make([]object.Object, length+1)
+			copy(newElements, array.Elements)
+			newElements[length] = args[1]
+
+			return &object.Array{Elements: newElements}
+		},
+	},
+}
+
+// BuiltinNames lists the sorted names of the builtin functions
+func BuiltinNames() []string {
+	names := []string{}
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func arrayArgument(name string, args []object.Object) (*object.Array, *object.Error) {
+	if len(args) != 1 {
+		return nil, newBuiltinError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	array, ok := args[0].(*object.Array)
+	if !ok {
+		return nil, newBuiltinError("argument to `%s` must be ARRAY, got %s", name, args[0].Type())
+	}
+	return array, nil
+}
+
+// newBuiltinError builds an error without a position; applyFunction
+// fills in the position of the call
+func newBuiltinError(format string, a ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...)}
+}
@@ -0,0 +1,571 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"monkey/ast"
+	"monkey/object"
+)
+
+var (
+	NULL  = &object.Null{}
+	TRUE  = &object.Boolean{Value: true}
+	FALSE = &object.Boolean{Value: false}
+)
+
+// Evaluation Entrypoint
+
+func Eval(node ast.Node, env *object.Environment) object.Object {
+	switch node := node.(type) {
+
+	// Statements
+	case *ast.Program:
+		return evalProgram(node, env)
+	case *ast.BlockStatement:
+		return evalBlockStatement(node, env)
+	case *ast.ExpressionStatement:
+		return Eval(node.Expression, env)
+	case *ast.LetStatement:
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+		env.Set(node.Name.Value, val)
+	case *ast.AssignStatement:
+		return evalAssignStatement(node, env)
+	case *ast.ReturnStatement:
+		if node.ReturnValue == nil {
+			return &object.ReturnValue{Value: NULL}
+		}
+		val := Eval(node.ReturnValue, env)
+		if isError(val) {
+			return val
+		}
+		return &object.ReturnValue{Value: val}
+
+	// Literals
+	case *ast.IntegerLiteral:
+		if node.IsBig() {
+			return &object.Integer{Big: new(big.Int).Set(node.Big)}
+		}
+		return &object.Integer{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+	case *ast.StringLiteral:
+		return &object.String{Value: node.Value}
+	case *ast.Boolean:
+		return nativeBoolToBooleanObject(node.Value)
+	case *ast.ArrayLiteral:
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isError(elements[0]) {
+			return elements[0]
+		}
+		return &object.Array{Elements: elements}
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
+	case *ast.FunctionLiteral:
+		return &object.Function{Parameters: node.Parameters, Body: node.Body, Env: env}
+
+	// Expressions
+	case *ast.Identifier:
+		return evalIdentifier(node, env)
+	case *ast.GroupedExpression:
+		return Eval(node.Expression, env)
+	case *ast.PrefixExpression:
+		right := Eval(node.Right, env)
+		if isError(right) {
+			return right
+		}
+		return evalPrefixExpression(node, node.Operator, right)
+	case *ast.InfixExpression:
+		left := Eval(node.Left, env)
+		if isError(left) {
+			return left
+		}
+		right := Eval(node.Right, env)
+		if isError(right) {
+			return right
+		}
+		return evalInfixExpression(node, node.Operator, left, right)
+	case *ast.LogicalExpression:
+		return evalLogicalExpression(node, env)
+	case *ast.IfExpression:
+		return evalIfExpression(node, env)
+	case *ast.CallExpression:
+		function := Eval(node.Function, env)
+		if isError(function) {
+			return function
+		}
+		args := evalExpressions(node.Arguments, env)
+		if len(args) == 1 && isError(args[0]) {
+			return args[0]
+		}
+		return applyFunction(node, function, args)
+	case *ast.IndexExpression:
+		left := Eval(node.Left, env)
+		if isError(left) {
+			return left
+		}
+		index := Eval(node.Index, env)
+		if isError(index) {
+			return index
+		}
+		return evalIndexExpression(node, left, index)
+	}
+
+	return nil
+}
+
+// Statement Evaluation Functions
+
+func evalProgram(program *ast.Program, env *object.Environment) object.Object {
+	var result object.Object
+
+	for _, statement := range program.Statements {
+		result = Eval(statement, env)
+
+		switch result := result.(type) {
+		case *object.ReturnValue:
+			return result.Value
+		case *object.Error:
+			return result
+		}
+	}
+
+	return result
+}
+
+// evalBlockStatement leaves return values wrapped so they unwind every
+// enclosing block up to the function call
+func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
+	var result object.Object
+
+	for _, statement := range block.Statements {
+		result = Eval(statement, env)
+
+		if result != nil {
+			rt := result.Type()
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+				return result
+			}
+		}
+	}
+
+	if result == nil {
+		return NULL
+	}
+	return result
+}
+
+func evalAssignStatement(node *ast.AssignStatement, env *object.Environment) object.Object {
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	current, ok := env.Get(node.Name.Value)
+	if !ok {
+		return newError(node.Name, "identifier not found: %s", node.Name.Value)
+	}
+
+	// Compound operators apply their arithmetic operator to the current value
+	if node.Operator != "=" {
+		val = evalInfixExpression(node, node.Operator[:1], current, val)
+		if isError(val) {
+			return val
+		}
+	}
+
+	env.Assign(node.Name.Value, val)
+	return nil
+}
+
+// Expression Evaluation Functions
+
+func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
+	if val, ok := env.Get(node.Value); ok {
+		return val
+	}
+	if builtin, ok := builtins[node.Value]; ok {
+		return builtin
+	}
+	return newError(node, "identifier not found: %s", node.Value)
+}
+
+func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
+	var result []object.Object
+
+	for _, e := range exps {
+		evaluated := Eval(e, env)
+		if isError(evaluated) {
+			return []object.Object{evaluated}
+		}
+		result = append(result, evaluated)
+	}
+
+	return result
+}
+
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := object.NewHash()
+
+	for _, pair := range node.Pairs {
+		key := Eval(pair.Key, env)
+		if isError(key) {
+			return key
+		}
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError(pair.Key, "unusable as hash key: %s", key.Type())
+		}
+
+		value := Eval(pair.Value, env)
+		if isError(value) {
+			return value
+		}
+
+		hash.Set(hashKey, object.HashPair{Key: key, Value: value})
+	}
+
+	return hash
+}
+
+func evalPrefixExpression(node ast.Node, operator string, right object.Object) object.Object {
+	switch operator {
+	case "!":
+		return nativeBoolToBooleanObject(!isTruthy(right))
+	case "-":
+		return evalMinusPrefixOperatorExpression(node, right)
+	default:
+		return newError(node, "unknown operator: %s%s", operator, right.Type())
+	}
+}
+
+func evalMinusPrefixOperatorExpression(node ast.Node, right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		if right.IsBig() || right.Value == math.MinInt64 {
+			return object.NewBigInteger(right.BigValue().Neg(right.BigValue()))
+		}
+		return &object.Integer{Value: -right.Value}
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	default:
+		return newError(node, "unknown operator: -%s", right.Type())
+	}
+}
+
+func evalInfixExpression(node ast.Node, operator string, left, right object.Object) object.Object {
+	switch {
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
+		return evalIntegerInfixExpression(node, operator, left.(*object.Integer), right.(*object.Integer))
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpression(node, operator, toFloat(left), toFloat(right))
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return evalStringInfixExpression(node, operator, left.(*object.String).Value, right.(*object.String).Value)
+	case operator == "==":
+		return nativeBoolToBooleanObject(left == right)
+	case operator == "!=":
+		return nativeBoolToBooleanObject(left != right)
+	case left.Type() != right.Type():
+		return newError(node, "type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	default:
+		return newError(node, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalIntegerInfixExpression computes on int64 and switches to math/big
+// only when an operand is big or the result overflows
+func evalIntegerInfixExpression(node ast.Node, operator string, left, right *object.Integer) object.Object {
+	if operator == "/" && !right.IsBig() && right.Value == 0 {
+		return newError(node, "division by zero")
+	}
+	if left.IsBig() || right.IsBig() {
+		return evalBigIntegerInfixExpression(node, operator, left.BigValue(), right.BigValue())
+	}
+
+	a, b := left.Value, right.Value
+	switch operator {
+	case "+":
+		sum := a + b
+		if (a^sum)&(b^sum) < 0 {
+			break
+		}
+		return &object.Integer{Value: sum}
+	case "-":
+		difference := a - b
+		if (a^b)&(a^difference) < 0 {
+			break
+		}
+		return &object.Integer{Value: difference}
+	case "*":
+		product := a * b
+		if a != 0 && (product/a != b || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64)) {
+			break
+		}
+		return &object.Integer{Value: product}
+	case "/":
+		if a == math.MinInt64 && b == -1 {
+			break
+		}
+		return &object.Integer{Value: a / b}
+	case "<":
+		return nativeBoolToBooleanObject(a < b)
+	case "<=":
+		return nativeBoolToBooleanObject(a <= b)
+	case ">":
+		return nativeBoolToBooleanObject(a > b)
+	case ">=":
+		return nativeBoolToBooleanObject(a >= b)
+	case "==":
+		return nativeBoolToBooleanObject(a == b)
+	case "!=":
+		return nativeBoolToBooleanObject(a != b)
+	default:
+		return newError(node, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+
+	// The int64 result overflowed
+	return evalBigIntegerInfixExpression(node, operator, left.BigValue(), right.BigValue())
+}
+
+func evalBigIntegerInfixExpression(node ast.Node, operator string, a, b *big.Int) object.Object {
+	switch operator {
+	case "+":
+		return object.NewBigInteger(a.Add(a, b))
+	case "-":
+		return object.NewBigInteger(a.Sub(a, b))
+	case "*":
+		return object.NewBigInteger(a.Mul(a, b))
+	case "/":
+		return object.NewBigInteger(a.Quo(a, b))
+	case "<":
+		return nativeBoolToBooleanObject(a.Cmp(b) < 0)
+	case "<=":
+		return nativeBoolToBooleanObject(a.Cmp(b) <= 0)
+	case ">":
+		return nativeBoolToBooleanObject(a.Cmp(b) > 0)
+	case ">=":
+		return nativeBoolToBooleanObject(a.Cmp(b) >= 0)
+	case "==":
+		return nativeBoolToBooleanObject(a.Cmp(b) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(a.Cmp(b) != 0)
+	default:
+		return newError(node, "unknown operator: %s %s %s", object.INTEGER_OBJ, operator, object.INTEGER_OBJ)
+	}
+}
+
+func evalFloatInfixExpression(node ast.Node, operator string, a, b float64) object.Object {
+	switch operator {
+	case "+":
+		return &object.Float{Value: a + b}
+	case "-":
+		return &object.Float{Value: a - b}
+	case "*":
+		return &object.Float{Value: a * b}
+	case "/":
+		return &object.Float{Value: a / b}
+	case "<":
+		return nativeBoolToBooleanObject(a < b)
+	case "<=":
+		return nativeBoolToBooleanObject(a <= b)
+	case ">":
+		return nativeBoolToBooleanObject(a > b)
+	case ">=":
+		return nativeBoolToBooleanObject(a >= b)
+	case "==":
+		return nativeBoolToBooleanObject(a == b)
+	case "!=":
+		return nativeBoolToBooleanObject(a != b)
+	default:
+		return newError(node, "unknown operator: %s %s %s", object.FLOAT_OBJ, operator, object.FLOAT_OBJ)
+	}
+}
+
+func evalStringInfixExpression(node ast.Node, operator string, a, b string) object.Object {
+	switch operator {
+	case "+":
+		return &object.String{Value: a + b}
+	case "<":
+		return nativeBoolToBooleanObject(a < b)
+	case "<=":
+		return nativeBoolToBooleanObject(a <= b)
+	case ">":
+		return nativeBoolToBooleanObject(a > b)
+	case ">=":
+		return nativeBoolToBooleanObject(a >= b)
+	case "==":
+		return nativeBoolToBooleanObject(a == b)
+	case "!=":
+		return nativeBoolToBooleanObject(a != b)
+	default:
+		return newError(node, "unknown operator: %s %s %s", object.STRING_OBJ, operator, object.STRING_OBJ)
+	}
+}
+
+// evalLogicalExpression returns the operand that decided the result, so the
+// right operand is only evaluated when the left one does not
+func evalLogicalExpression(node *ast.LogicalExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	switch node.Operator {
+	case "&&":
+		if !isTruthy(left) {
+			return left
+		}
+	case "||":
+		if isTruthy(left) {
+			return left
+		}
+	default:
+		return newError(node, "unknown operator: %s %s", left.Type(), node.Operator)
+	}
+
+	return Eval(node.Right, env)
+}
+
+func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
+	condition := Eval(ie.Condition, env)
+	if isError(condition) {
+		return condition
+	}
+
+	if isTruthy(condition) {
+		return Eval(ie.Consequence, env)
+	} else if ie.Alternative != nil {
+		return Eval(ie.Alternative, env)
+	} else {
+		return NULL
+	}
+}
+
+func evalIndexExpression(node ast.Node, left, index object.Object) object.Object {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalArrayIndexExpression(left.(*object.Array), index.(*object.Integer))
+	case left.Type() == object.HASH_OBJ:
+		return evalHashIndexExpression(node, left.(*object.Hash), index)
+	default:
+		return newError(node, "index operator not supported: %s[%s]", left.Type(), index.Type())
+	}
+}
+
+func evalArrayIndexExpression(array *object.Array, index *object.Integer) object.Object {
+	max := int64(len(array.Elements) - 1)
+
+	if index.IsBig() || index.Value < 0 || index.Value > max {
+		return NULL
+	}
+
+	return array.Elements[index.Value]
+}
+
+func evalHashIndexExpression(node ast.Node, hash *object.Hash, index object.Object) object.Object {
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return newError(node, "unusable as hash key: %s", index.Type())
+	}
+
+	pair, ok := hash.Pairs[key.HashKey()]
+	if !ok {
+		return NULL
+	}
+
+	return pair.Value
+}
+
+// Function Application
+
+func applyFunction(node ast.Node, fn object.Object, args []object.Object) object.Object {
+	switch fn := fn.(type) {
+	case *object.Function:
+		if len(args) != len(fn.Parameters) {
+			return newError(node, "wrong number of arguments: want=%d, got=%d", len(fn.Parameters), len(args))
+		}
+		extendedEnv := extendFunctionEnv(fn, args)
+		evaluated := Eval(fn.Body, extendedEnv)
+		return unwrapReturnValue(evaluated)
+	case *object.Builtin:
+		result := fn.Fn(args...)
+		// Builtins do not know where they were called from
+		if err, ok := result.(*object.Error); ok && err.Pos.Line == 0 {
+			err.Pos = node.Pos()
+		}
+		return result
+	default:
+		return newError(node, "not a function: %s", fn.Type())
+	}
+}
+
+func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
+	env := object.NewEnclosedEnvironment(fn.Env)
+
+	for i, param := range fn.Parameters {
+		env.Set(param.Value, args[i])
+	}
+
+	return env
+}
+
+func unwrapReturnValue(obj object.Object) object.Object {
+	if returnValue, ok := obj.(*object.ReturnValue); ok {
+		return returnValue.Value
+	}
+	return obj
+}
+
+// Utilities
+
+func nativeBoolToBooleanObject(input bool) *object.Boolean {
+	if input {
+		return TRUE
+	}
+	return FALSE
+}
+
+func isTruthy(obj object.Object) bool {
+	switch obj {
+	case NULL:
+		return false
+	case FALSE:
+		return false
+	default:
+		return true
+	}
+}
+
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		if obj.IsBig() {
+			value, _ := new(big.Float).SetInt(obj.Big).Float64()
+			return value
+		}
+		return float64(obj.Value)
+	case *object.Float:
+		return obj.Value
+	}
+	return math.NaN()
+}
+
+// Error tracking methods
+
+func newError(node ast.Node, format string, a ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...), Pos: node.Pos()}
+}
+
+func isError(obj object.Object) bool {
+	if obj != nil {
+		return obj.Type() == object.ERROR_OBJ
+	}
+	return false
+}
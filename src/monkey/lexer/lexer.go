@@ -1,18 +1,57 @@
 package lexer
 
-import "monkey/token"
+import (
+	"fmt"
+	"monkey/token"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
 
 type Lexer struct {
 	input        string
-	position     int  // Current cursor
-	readPosition int  // Next character to be searched
-	ch           byte // Character currently evaluated
+	position     int  // Current cursor, as a byte offset
+	readPosition int  // Next character to be searched, as a byte offset
+	ch           rune // Character currently evaluated
+	width        int  // Byte width of the current character
+	line         int  // Line of the current character
+	column       int  // Column of the current character, counted in runes
+
+	errors []Error
+
+	keepComments bool
+}
+
+// Error is a positioned lexical error. Every error lies inside the ILLEGAL
+// token it was reported for.
+type Error struct {
+	Pos      token.Position
+	Msg      string
+	Unclosed bool // The input ended inside a block comment, so more input could close it
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+type Option func(*Lexer)
+
+// WithComments keeps comments as COMMENT tokens attached to the token that
+// follows them instead of discarding them
+func WithComments() Option {
+	return func(l *Lexer) {
+		l.keepComments = true
+	}
 }
 
 // Lexer initializer
 
-func New(input string) *Lexer {
-	l := &Lexer{input: input}
+func New(input string, options ...Option) *Lexer {
+	l := &Lexer{input: input, position: -1, line: 1, column: 1}
+	for _, option := range options {
+		option(l)
+	}
 	l.readChar()
 	return l
 }
@@ -22,7 +61,24 @@ func New(input string) *Lexer {
 func (l *Lexer) NextToken() token.Token {
 	var tok token.Token
 
-	l.skipWhitespace()
+	comments := l.skipTrivia()
+	if n := len(comments); n > 0 && comments[n-1].Type == token.ILLEGAL {
+		// An unterminated block comment stands in for the next token
+		tok, comments = comments[n-1], comments[:n-1]
+	} else {
+		tok = l.readToken()
+	}
+
+	if l.keepComments && len(comments) > 0 {
+		tok.Comments = comments
+	}
+	return tok
+}
+
+func (l *Lexer) readToken() token.Token {
+	var tok token.Token
+
+	pos := l.currentPosition()
 	switch l.ch {
 
 	// Operator
@@ -45,11 +101,19 @@ func (l *Lexer) NextToken() token.Token {
 	case '>':
 		tok = l.peekCharAndMakeToken('=', token.GE, token.GT)
 
+	// Logical
+	case '&':
+		tok = l.readLogicalOperator('&', token.AND)
+	case '|':
+		tok = l.readLogicalOperator('|', token.OR)
+
 	// Separators
 	case ';':
 		tok = newToken(token.SEMICOLON, l.ch)
 	case ',':
 		tok = newToken(token.COMMA, l.ch)
+	case ':':
+		tok = newToken(token.COLON, l.ch)
 
 	// Parantheses
 	case '(':
@@ -60,63 +124,400 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.LBRACE, l.ch)
 	case '}':
 		tok = newToken(token.RBRACE, l.ch)
+	case '[':
+		tok = newToken(token.LBRACKET, l.ch)
+	case ']':
+		tok = newToken(token.RBRACKET, l.ch)
+
+	// Literals
+	case '"':
+		value, ok := l.readString(pos)
+		if ok {
+			tok = token.Token{Type: token.STRING, Literal: value}
+		} else {
+			tok = token.Token{Type: token.ILLEGAL, Literal: l.input[pos.Offset:l.position]}
+		}
+		return l.locate(tok, pos)
 
 	// Error check
 	case 0:
+		if l.position < len(l.input) {
+			tok = newToken(token.ILLEGAL, l.ch)
+			l.addError(pos, "unexpected NUL character")
+			break
+		}
 		tok.Literal = ""
 		tok.Type = token.EOF
+		return l.locate(tok, pos)
 	default:
-		if isLetter(l.ch) {
+		if l.ch == utf8.RuneError && l.width == 1 {
+			tok = token.Token{Type: token.ILLEGAL, Literal: l.input[l.position : l.position+1]}
+			l.addError(pos, fmt.Sprintf("invalid UTF-8 encoding %q", tok.Literal))
+		} else if isLetter(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
-			return tok
+			return l.locate(tok, pos)
 		} else if isDigit(l.ch) {
-			tok.Type = token.INT
-			tok.Literal = l.readNumber()
-			return tok
+			tok.Literal, tok.Type = l.readNumber()
+			if !l.validateNumber(pos, tok.Literal, tok.Type) {
+				tok.Type = token.ILLEGAL
+			}
+			return l.locate(tok, pos)
 		} else {
 			tok = newToken(token.ILLEGAL, l.ch)
+			l.addError(pos, fmt.Sprintf("illegal character %q", l.ch))
 		}
 	}
 
 	l.readChar()
-	return tok
+	return l.locate(tok, pos)
 }
 
 // Token separator
 
 func (l *Lexer) readIdentifier() string {
 	position := l.position
-	for isLetter(l.ch) {
+	for isLetter(l.ch) || unicode.IsDigit(l.ch) {
 		l.readChar()
 	}
 	return l.input[position:l.position]
 }
 
-func (l *Lexer) readNumber() string {
+// readString reads a double-quoted string starting at the opening quote and
+// returns its decoded value. It reports false after recording an error for
+// an unterminated string or a malformed escape sequence.
+func (l *Lexer) readString(start token.Position) (string, bool) {
+	var out strings.Builder
+	ok := true
+
+	for {
+		l.readChar()
+		switch {
+		case l.ch == '"':
+			l.readChar()
+			return out.String(), ok
+		case l.ch == 0 && l.position >= len(l.input), l.ch == '\n', l.ch == '\r':
+			l.addError(start, "unterminated string literal")
+			return out.String(), false
+		case l.ch == utf8.RuneError && l.width == 1:
+			l.addError(l.currentPosition(), fmt.Sprintf("invalid UTF-8 encoding %q", l.input[l.position:l.position+1]))
+			ok = false
+		case l.ch == '\\':
+			if !l.readEscape(&out) {
+				ok = false
+			}
+		default:
+			out.WriteRune(l.ch)
+		}
+	}
+}
+
+// readEscape decodes the escape sequence starting at the current backslash,
+// leaving the lexer on its last character
+func (l *Lexer) readEscape(out *strings.Builder) bool {
+	pos := l.currentPosition()
+
+	switch l.peekChar() {
+	case 'n':
+		out.WriteRune('\n')
+	case 't':
+		out.WriteRune('\t')
+	case '"':
+		out.WriteRune('"')
+	case '\\':
+		out.WriteRune('\\')
+	case 'u':
+		l.readChar()
+		return l.readUnicodeEscape(pos, out)
+	case 0, '\n', '\r':
+		// Leave the line end to readString so it reports the unterminated string
+		return false
+	default:
+		l.addError(pos, fmt.Sprintf("unknown escape sequence \\%c", l.peekChar()))
+		l.readChar()
+		return false
+	}
+	l.readChar()
+	return true
+}
+
+// readUnicodeEscape decodes the `{...}` part of a `\u{...}` escape holding
+// one to six hex digits
+func (l *Lexer) readUnicodeEscape(pos token.Position, out *strings.Builder) bool {
+	if l.peekChar() != '{' {
+		l.addError(pos, "unicode escape must have the form \\u{XXXX}")
+		return false
+	}
+	l.readChar()
+
+	digits := ""
+	for isHexDigit(l.peekChar()) {
+		l.readChar()
+		digits += string(l.ch)
+	}
+	if l.peekChar() != '}' || len(digits) == 0 || len(digits) > 6 {
+		l.addError(pos, "unicode escape must have the form \\u{XXXX}")
+		return false
+	}
+	l.readChar()
+
+	value, _ := strconv.ParseUint(digits, 16, 32)
+	if !utf8.ValidRune(rune(value)) {
+		l.addError(pos, fmt.Sprintf("escape sequence \\u{%s} is not a valid code point", digits))
+		return false
+	}
+	out.WriteRune(rune(value))
+	return true
+}
+
+// readNumber reads the whole run of letters, digits and underscores that
+// starts with a digit, so a malformed literal such as `123abc` stays one
+// token for validateNumber to reject. A decimal literal becomes a FLOAT
+// only when it has a fraction (`1.5`) or an exponent with digits (`1e-3`);
+// `1e` or `1e+` are malformed integers.
+func (l *Lexer) readNumber() (string, token.TokenType) {
 	position := l.position
-	for isDigit(l.ch) {
+	prefixed := l.ch == '0' && strings.ContainsRune("xXoObB", l.peekChar())
+
+	float := l.readNumberRun(prefixed)
+	if !prefixed && l.ch == '.' && isDigit(l.peekChar()) {
 		l.readChar()
+		l.readNumberRun(prefixed)
+		float = true
 	}
-	return l.input[position:l.position]
+
+	literal := l.input[position:l.position]
+	if float {
+		return literal, token.FLOAT
+	}
+	return literal, token.INT
+}
+
+// readNumberRun reads letters, digits and underscores, plus the sign of an
+// exponent in a decimal literal. It reports whether the run held an
+// exponent marker followed by digits.
+func (l *Lexer) readNumberRun(prefixed bool) bool {
+	exponent := false
+	for isLetter(l.ch) || unicode.IsDigit(l.ch) {
+		if !prefixed && (l.ch == 'e' || l.ch == 'E') {
+			switch next := l.peekChar(); {
+			case isDigit(next):
+				exponent = true
+			case (next == '+' || next == '-') && l.readPosition+1 < len(l.input) && isDigit(rune(l.input[l.readPosition+1])):
+				exponent = true
+				l.readChar()
+			}
+		}
+		l.readChar()
+	}
+	return exponent
+}
+
+var numberBases = map[byte]struct {
+	base int
+	name string
+}{
+	'x': {16, "hexadecimal"},
+	'X': {16, "hexadecimal"},
+	'o': {8, "octal"},
+	'O': {8, "octal"},
+	'b': {2, "binary"},
+	'B': {2, "binary"},
+}
+
+// validateNumber checks a literal read by readNumber and records an error at
+// the first offending character. Digits may be separated by single
+// underscores, also right after a 0x, 0o or 0b prefix.
+func (l *Lexer) validateNumber(start token.Position, literal string, tokenType token.TokenType) bool {
+	if tokenType == token.FLOAT {
+		return l.validateFloat(start, literal)
+	}
+
+	base, name, digits := 10, "decimal", literal
+	if len(literal) >= 2 && literal[0] == '0' {
+		if prefix, ok := numberBases[literal[1]]; ok {
+			base, name, digits = prefix.base, prefix.name, literal[2:]
+		}
+	}
+	prefixLength := len(literal) - len(digits)
+
+	errorAt := func(offset int, msg string) bool {
+		pos := start
+		pos.Offset += offset
+		pos.Column += utf8.RuneCountInString(literal[:offset])
+		l.addError(pos, msg)
+		return false
+	}
+
+	count, offset, msg := scanDigits(digits, base, name)
+	if msg != "" {
+		return errorAt(prefixLength+offset, msg)
+	}
+	if count == 0 {
+		return errorAt(0, fmt.Sprintf("%s literal has no digits", name))
+	}
+	return true
+}
+
+// validateFloat checks a FLOAT literal of the form digits[.digits][e[+-]digits]
+func (l *Lexer) validateFloat(start token.Position, literal string) bool {
+	errorAt := func(offset int, msg string) bool {
+		pos := start
+		pos.Offset += offset
+		pos.Column += utf8.RuneCountInString(literal[:offset])
+		l.addError(pos, msg)
+		return false
+	}
+
+	mantissa, exponent := literal, ""
+	exponentStart := strings.IndexAny(literal, "eE")
+	if exponentStart >= 0 {
+		mantissa, exponent = literal[:exponentStart], literal[exponentStart+1:]
+	}
+
+	integer, fraction := mantissa, ""
+	fractionStart := strings.IndexByte(mantissa, '.')
+	if fractionStart >= 0 {
+		integer, fraction = mantissa[:fractionStart], mantissa[fractionStart+1:]
+	}
+
+	if _, offset, msg := scanDigits(integer, 10, "float"); msg != "" {
+		return errorAt(offset, msg)
+	}
+	if fractionStart >= 0 {
+		if _, offset, msg := scanDigits(fraction, 10, "float"); msg != "" {
+			return errorAt(fractionStart+1+offset, msg)
+		}
+	}
+	if exponentStart >= 0 {
+		signLength := 0
+		if strings.HasPrefix(exponent, "+") || strings.HasPrefix(exponent, "-") {
+			signLength = 1
+		}
+		digits := exponent[signLength:]
+		if strings.HasPrefix(digits, "_") {
+			return errorAt(exponentStart+1+signLength, "'_' must separate successive digits")
+		}
+		count, offset, msg := scanDigits(digits, 10, "float")
+		if msg != "" {
+			return errorAt(exponentStart+1+signLength+offset, msg)
+		}
+		if count == 0 {
+			return errorAt(exponentStart, "exponent has no digits")
+		}
+	}
+	return true
+}
+
+// scanDigits counts the digits of the given base and reports the offset and
+// message of the first character that does not belong, if any
+func scanDigits(digits string, base int, name string) (int, int, string) {
+	count := 0
+	var prev rune
+	for offset, ch := range digits {
+		switch {
+		case ch == '_':
+			if prev == '_' {
+				return count, offset, "'_' must separate successive digits"
+			}
+		case digitValue(ch) < base:
+			count += 1
+		case isDigit(ch):
+			return count, offset, fmt.Sprintf("invalid digit %q in %s literal", ch, name)
+		default:
+			return count, offset, fmt.Sprintf("invalid character %q in %s literal", ch, name)
+		}
+		prev = ch
+	}
+	if prev == '_' && count > 0 {
+		return count, len(digits) - 1, "'_' must separate successive digits"
+	}
+	return count, 0, ""
+}
+
+// digitValue returns the value of a hex digit, or 16 for any other character
+func digitValue(ch rune) int {
+	switch {
+	case isDigit(ch):
+		return int(ch - '0')
+	case 'a' <= ch && ch <= 'f':
+		return int(ch - 'a' + 10)
+	case 'A' <= ch && ch <= 'F':
+		return int(ch - 'A' + 10)
+	}
+	return 16
 }
 
 // Character validity check methods
 
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+func isLetter(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '_'
 }
 
-func isDigit(ch byte) bool {
+// isDigit only accepts ASCII digits; other Unicode digits may appear
+// inside identifiers but never start a number
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
+}
+
 // Token generation methods
 
-func newToken(tokenType token.TokenType, ch byte) token.Token {
+func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
 
+// locate stamps the token with its start and the position right after it
+func (l *Lexer) locate(tok token.Token, pos token.Position) token.Token {
+	tok.Pos = pos
+	tok.End = l.currentPosition()
+	return tok
+}
+
+// Error tracking methods
+
+func (l *Lexer) Errors() []Error {
+	return l.errors
+}
+
+// ErrorFor returns the first error reported inside the given token
+func (l *Lexer) ErrorFor(tok token.Token) (Error, bool) {
+	for _, e := range l.errors {
+		if e.Pos == tok.Pos || tok.Pos.Offset <= e.Pos.Offset && e.Pos.Offset < tok.End.Offset {
+			return e, true
+		}
+	}
+	return Error{}, false
+}
+
+func (l *Lexer) addError(pos token.Position, msg string) {
+	l.errors = append(l.errors, Error{Pos: pos, Msg: msg})
+}
+
+// Methods for tracking positions
+
+func (l *Lexer) currentPosition() token.Position {
+	return token.Position{Offset: l.position, Line: l.line, Column: l.column}
+}
+
+// advancePosition moves line and column past the current character,
+// treating "\n", "\r\n" and a lone "\r" as one line break
+func (l *Lexer) advancePosition() {
+	switch {
+	case l.ch == '\n':
+		l.line += 1
+		l.column = 1
+	case l.ch == '\r' && l.peekChar() != '\n':
+		l.line += 1
+		l.column = 1
+	default:
+		l.column += 1
+	}
+}
+
 // Methods for reading input
 
 func (l *Lexer) skipWhitespace() {
@@ -125,27 +526,92 @@ func (l *Lexer) skipWhitespace() {
 	}
 }
 
+// skipTrivia skips whitespace and comments and returns the comments as
+// COMMENT tokens; an unterminated block comment comes back as ILLEGAL and
+// is always last
+func (l *Lexer) skipTrivia() []token.Token {
+	comments := []token.Token{}
+
+	for {
+		l.skipWhitespace()
+		if l.ch != '/' {
+			return comments
+		}
+
+		pos := l.currentPosition()
+		switch l.peekChar() {
+		case '/':
+			l.readLineComment()
+		case '*':
+			if !l.readBlockComment(pos) {
+				tok := token.Token{Type: token.ILLEGAL, Literal: l.input[pos.Offset:l.position]}
+				return append(comments, l.locate(tok, pos))
+			}
+		default:
+			return comments
+		}
+		tok := token.Token{Type: token.COMMENT, Literal: l.input[pos.Offset:l.position]}
+		comments = append(comments, l.locate(tok, pos))
+	}
+}
+
+// readLineComment reads a `//` comment up to, but not including, the line end
+func (l *Lexer) readLineComment() {
+	for l.ch != '\n' && l.ch != '\r' && !(l.ch == 0 && l.position >= len(l.input)) {
+		l.readChar()
+	}
+}
+
+// readBlockComment reads a `/* */` comment, which may nest, and reports
+// false after recording an error when the input ends before it is closed
+func (l *Lexer) readBlockComment(start token.Position) bool {
+	depth := 0
+	for {
+		switch {
+		case l.ch == 0 && l.position >= len(l.input):
+			l.errors = append(l.errors, Error{Pos: start, Msg: "unterminated block comment", Unclosed: true})
+			return false
+		case l.ch == '/' && l.peekChar() == '*':
+			depth += 1
+			l.readChar()
+		case l.ch == '*' && l.peekChar() == '/':
+			depth -= 1
+			l.readChar()
+			if depth == 0 {
+				l.readChar()
+				return true
+			}
+		}
+		l.readChar()
+	}
+}
+
 func (l *Lexer) readChar() {
+	if l.position >= 0 && l.position < len(l.input) {
+		l.advancePosition()
+	}
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
+		l.width = 0
 	} else {
-		l.ch = l.input[l.readPosition]
+		l.ch, l.width = utf8.DecodeRuneInString(l.input[l.readPosition:])
 	}
 	l.position = l.readPosition
-	l.readPosition += 1
+	l.readPosition += l.width
 }
 
-func (l *Lexer) peekChar() byte {
+func (l *Lexer) peekChar() rune {
 	if l.readPosition >= len(l.input) {
 		return 0
 	} else {
-		return l.input[l.readPosition]
+		ch, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+		return ch
 	}
 }
 
 // Methods for building double character tokens
 
-func (l *Lexer) peekCharAndMakeToken(crit byte, wt_type token.TokenType, wo_type token.TokenType) token.Token {
+func (l *Lexer) peekCharAndMakeToken(crit rune, wt_type token.TokenType, wo_type token.TokenType) token.Token {
 	if l.peekChar() == crit {
 		literal := l.buildDoubleCharacterLiteral()
 		return token.Token{Type: wt_type, Literal: literal}
@@ -154,6 +620,15 @@ func (l *Lexer) peekCharAndMakeToken(crit byte, wt_type token.TokenType, wo_type
 	}
 }
 
+// readLogicalOperator builds `&&` or `||`; a single `&` or `|` is illegal
+func (l *Lexer) readLogicalOperator(ch rune, tokenType token.TokenType) token.Token {
+	if l.peekChar() == ch {
+		return token.Token{Type: tokenType, Literal: l.buildDoubleCharacterLiteral()}
+	}
+	l.addError(l.currentPosition(), fmt.Sprintf("illegal character %q, did you mean %q?", ch, string(tokenType)))
+	return newToken(token.ILLEGAL, ch)
+}
+
 func (l *Lexer) buildDoubleCharacterLiteral() string {
 	ch := l.ch
 	l.readChar()
@@ -23,7 +23,7 @@ func evaulateTestcases(lex *Lexer, tcs []TokenTestcase, t *testing.T) {
 }
 
 func TestSingleCharacterTokens(t *testing.T) {
-	input := `=+(){},;-!/*<>`
+	input := `=+(){},;-!/ *<>` // "/*" would open a block comment
 
 	tests := []TokenTestcase{
 		{token.ASSIGN, "="},
@@ -152,3 +152,425 @@ func TestDoubleCharacterTokens(t *testing.T) {
 	lex := New(input)
 	evaulateTestcases(lex, tests, t)
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := "let x = 10;\n  x >= 5\r\n!x\ry"
+
+	tests := []struct {
+		expectedType  token.TokenType
+		expectedStart token.Position
+		expectedEnd   token.Position
+	}{
+		{token.LET, token.Position{Offset: 0, Line: 1, Column: 1}, token.Position{Offset: 3, Line: 1, Column: 4}},
+		{token.IDENT, token.Position{Offset: 4, Line: 1, Column: 5}, token.Position{Offset: 5, Line: 1, Column: 6}},
+		{token.ASSIGN, token.Position{Offset: 6, Line: 1, Column: 7}, token.Position{Offset: 7, Line: 1, Column: 8}},
+		{token.INT, token.Position{Offset: 8, Line: 1, Column: 9}, token.Position{Offset: 10, Line: 1, Column: 11}},
+		{token.SEMICOLON, token.Position{Offset: 10, Line: 1, Column: 11}, token.Position{Offset: 11, Line: 1, Column: 12}},
+		{token.IDENT, token.Position{Offset: 14, Line: 2, Column: 3}, token.Position{Offset: 15, Line: 2, Column: 4}},
+		{token.GE, token.Position{Offset: 16, Line: 2, Column: 5}, token.Position{Offset: 18, Line: 2, Column: 7}},
+		{token.INT, token.Position{Offset: 19, Line: 2, Column: 8}, token.Position{Offset: 20, Line: 2, Column: 9}},
+		{token.BANG, token.Position{Offset: 22, Line: 3, Column: 1}, token.Position{Offset: 23, Line: 3, Column: 2}},
+		{token.IDENT, token.Position{Offset: 23, Line: 3, Column: 2}, token.Position{Offset: 24, Line: 3, Column: 3}},
+		{token.IDENT, token.Position{Offset: 25, Line: 4, Column: 1}, token.Position{Offset: 26, Line: 4, Column: 2}},
+		{token.EOF, token.Position{Offset: 26, Line: 4, Column: 2}, token.Position{Offset: 26, Line: 4, Column: 2}},
+		{token.EOF, token.Position{Offset: 26, Line: 4, Column: 2}, token.Position{Offset: 26, Line: 4, Column: 2}},
+	}
+
+	lex := New(input)
+	for i, tt := range tests {
+		tok := lex.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Pos != tt.expectedStart {
+			t.Errorf("tests[%d] - start wrong. expected=%+v, got=%+v", i, tt.expectedStart, tok.Pos)
+		}
+		if tok.End != tt.expectedEnd {
+			t.Errorf("tests[%d] - end wrong. expected=%+v, got=%+v", i, tt.expectedEnd, tok.End)
+		}
+	}
+}
+
+func TestUnicodeIdentifiers(t *testing.T) {
+	input := "let 변수 = café + x1 + ünïcödé_2;"
+
+	tests := []TokenTestcase{
+		{token.LET, "let"},
+		{token.IDENT, "변수"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "café"},
+		{token.PLUS, "+"},
+		{token.IDENT, "x1"},
+		{token.PLUS, "+"},
+		{token.IDENT, "ünïcödé_2"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	lex := New(input)
+	evaulateTestcases(lex, tests, t)
+	if len(lex.Errors()) != 0 {
+		t.Errorf("lexer reported errors: %v", lex.Errors())
+	}
+}
+
+func TestUnicodePositions(t *testing.T) {
+	input := "변수 = café;"
+
+	tests := []struct {
+		expectedLiteral string
+		expectedStart   token.Position
+		expectedEnd     token.Position
+	}{
+		{"변수", token.Position{Offset: 0, Line: 1, Column: 1}, token.Position{Offset: 6, Line: 1, Column: 3}},
+		{"=", token.Position{Offset: 7, Line: 1, Column: 4}, token.Position{Offset: 8, Line: 1, Column: 5}},
+		{"café", token.Position{Offset: 9, Line: 1, Column: 6}, token.Position{Offset: 14, Line: 1, Column: 10}},
+		{";", token.Position{Offset: 14, Line: 1, Column: 10}, token.Position{Offset: 15, Line: 1, Column: 11}},
+	}
+
+	lex := New(input)
+	for i, tt := range tests {
+		tok := lex.NextToken()
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Pos != tt.expectedStart || tok.End != tt.expectedEnd {
+			t.Errorf("tests[%d] - range wrong. expected=%+v-%+v, got=%+v-%+v", i, tt.expectedStart, tt.expectedEnd, tok.Pos, tok.End)
+		}
+	}
+}
+
+func TestInvalidUTF8(t *testing.T) {
+	input := "a \xff b"
+
+	tests := []TokenTestcase{
+		{token.IDENT, "a"},
+		{token.ILLEGAL, "\xff"},
+		{token.IDENT, "b"},
+		{token.EOF, ""},
+	}
+
+	lex := New(input)
+	evaulateTestcases(lex, tests, t)
+
+	errors := lex.Errors()
+	if len(errors) != 1 {
+		t.Fatalf("lexer has wrong number of errors. expected=1, got=%d", len(errors))
+	}
+	expected := `1:3: invalid UTF-8 encoding "\xff"`
+	if errors[0].Error() != expected {
+		t.Errorf("wrong error. expected=%q, got=%q", expected, errors[0].Error())
+	}
+}
+
+func TestStringLiterals(t *testing.T) {
+	input := `"foobar" "foo bar" "" "a\nb\tc" "say \"hi\"" "back\\slash" "\u{48}\u{1F600}" "변수"`
+
+	tests := []TokenTestcase{
+		{token.STRING, "foobar"},
+		{token.STRING, "foo bar"},
+		{token.STRING, ""},
+		{token.STRING, "a\nb\tc"},
+		{token.STRING, `say "hi"`},
+		{token.STRING, `back\slash`},
+		{token.STRING, "H\U0001F600"},
+		{token.STRING, "변수"},
+		{token.EOF, ""},
+	}
+
+	lex := New(input)
+	evaulateTestcases(lex, tests, t)
+	if len(lex.Errors()) != 0 {
+		t.Errorf("lexer reported errors: %v", lex.Errors())
+	}
+}
+
+func TestMalformedStringLiterals(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedLiteral string
+		expectedError   string
+	}{
+		{`"abc`, `"abc`, "1:1: unterminated string literal"},
+		{"\"abc\nx", `"abc`, "1:1: unterminated string literal"},
+		{`"a\qb"`, `"a\qb"`, `1:3: unknown escape sequence \q`},
+		{`"\u{}"`, `"\u{}"`, `1:2: unicode escape must have the form \u{XXXX}`},
+		{`"\u41"`, `"\u41"`, `1:2: unicode escape must have the form \u{XXXX}`},
+		{`"\u{D800}"`, `"\u{D800}"`, `1:2: escape sequence \u{D800} is not a valid code point`},
+	}
+
+	for _, tt := range tests {
+		lex := New(tt.input)
+		tok := lex.NextToken()
+		if tok.Type != token.ILLEGAL {
+			t.Fatalf("%q - tokentype wrong. expected=%q, got=%q", tt.input, token.ILLEGAL, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Errorf("%q - literal wrong. expected=%q, got=%q", tt.input, tt.expectedLiteral, tok.Literal)
+		}
+		errors := lex.Errors()
+		if len(errors) != 1 || errors[0].Error() != tt.expectedError {
+			t.Errorf("%q - errors wrong. expected=%q, got=%v", tt.input, tt.expectedError, errors)
+		}
+	}
+}
+
+func TestCommentsAreSkipped(t *testing.T) {
+	input := `// leading comment
+	let x = 5; // trailing comment
+	/* block /* nested */ still comment */ x /= 2;
+	x / y //`
+
+	tests := []TokenTestcase{
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "5"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.DIVASSIGN, "/="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.SLASH, "/"},
+		{token.IDENT, "y"},
+		{token.EOF, ""},
+	}
+
+	lex := New(input)
+	evaulateTestcases(lex, tests, t)
+	if len(lex.Errors()) != 0 {
+		t.Errorf("lexer reported errors: %v", lex.Errors())
+	}
+}
+
+func TestCommentsAsTrivia(t *testing.T) {
+	input := "// doc\n/* more */ let x = 1; // tail"
+
+	lex := New(input, WithComments())
+
+	tok := lex.NextToken()
+	if tok.Type != token.LET {
+		t.Fatalf("tokentype wrong. expected=%q, got=%q", token.LET, tok.Type)
+	}
+	expected := []string{"// doc", "/* more */"}
+	if len(tok.Comments) != len(expected) {
+		t.Fatalf("tok.Comments has wrong length. expected=%d, got=%d", len(expected), len(tok.Comments))
+	}
+	for i, literal := range expected {
+		if tok.Comments[i].Type != token.COMMENT || tok.Comments[i].Literal != literal {
+			t.Errorf("tok.Comments[%d] wrong. expected=%q, got=%+v", i, literal, tok.Comments[i])
+		}
+	}
+	if tok.Comments[1].Pos.String() != "2:1" {
+		t.Errorf("tok.Comments[1].Pos wrong. expected=2:1, got=%s", tok.Comments[1].Pos)
+	}
+
+	for tok.Type != token.EOF {
+		tok = lex.NextToken()
+	}
+	if len(tok.Comments) != 1 || tok.Comments[0].Literal != "// tail" {
+		t.Errorf("EOF comments wrong. got=%+v", tok.Comments)
+	}
+
+	plain := New(input)
+	if tok := plain.NextToken(); tok.Comments != nil {
+		t.Errorf("comments kept without WithComments. got=%+v", tok.Comments)
+	}
+}
+
+func TestUnterminatedBlockComment(t *testing.T) {
+	input := "x /* open /* nested */"
+
+	tests := []TokenTestcase{
+		{token.IDENT, "x"},
+		{token.ILLEGAL, "/* open /* nested */"},
+		{token.EOF, ""},
+	}
+
+	lex := New(input)
+	evaulateTestcases(lex, tests, t)
+
+	errors := lex.Errors()
+	if len(errors) != 1 || errors[0].Error() != "1:3: unterminated block comment" || !errors[0].Unclosed {
+		t.Errorf("errors wrong. got=%v", errors)
+	}
+}
+
+func TestIntegerLiteralForms(t *testing.T) {
+	input := "0xff 0XFF 0o17 0b1010 1_000_000 0x_dead_BEEF 007 0"
+
+	tests := []TokenTestcase{
+		{token.INT, "0xff"},
+		{token.INT, "0XFF"},
+		{token.INT, "0o17"},
+		{token.INT, "0b1010"},
+		{token.INT, "1_000_000"},
+		{token.INT, "0x_dead_BEEF"},
+		{token.INT, "007"},
+		{token.INT, "0"},
+		{token.EOF, ""},
+	}
+
+	lex := New(input)
+	evaulateTestcases(lex, tests, t)
+	if len(lex.Errors()) != 0 {
+		t.Errorf("lexer reported errors: %v", lex.Errors())
+	}
+}
+
+func TestMalformedIntegerLiterals(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"123abc", "1:4: invalid character 'a' in decimal literal"},
+		{"0x", "1:1: hexadecimal literal has no digits"},
+		{"0b_", "1:1: binary literal has no digits"},
+		{"0b102", "1:5: invalid digit '2' in binary literal"},
+		{"0o78", "1:4: invalid digit '8' in octal literal"},
+		{"0xfg", "1:4: invalid character 'g' in hexadecimal literal"},
+		{"1__0", "1:3: '_' must separate successive digits"},
+		{"10_", "1:3: '_' must separate successive digits"},
+		{"1e", "1:2: invalid character 'e' in decimal literal"},
+		{"2e_5", "1:2: invalid character 'e' in decimal literal"},
+		{"0b1e1", "1:4: invalid character 'e' in binary literal"},
+	}
+
+	for _, tt := range tests {
+		lex := New(tt.input)
+		tok := lex.NextToken()
+		if tok.Type != token.ILLEGAL || tok.Literal != tt.input {
+			t.Fatalf("%q - token wrong. expected ILLEGAL %q, got=%s %q", tt.input, tt.input, tok.Type, tok.Literal)
+		}
+		errors := lex.Errors()
+		if len(errors) != 1 || errors[0].Error() != tt.expectedError {
+			t.Errorf("%q - errors wrong. expected=%q, got=%v", tt.input, tt.expectedError, errors)
+		}
+	}
+}
+
+func TestExponentSignWithoutDigits(t *testing.T) {
+	input := "1e+ 2 1.5e- 3"
+
+	tests := []TokenTestcase{
+		{token.ILLEGAL, "1e"},
+		{token.PLUS, "+"},
+		{token.INT, "2"},
+		{token.ILLEGAL, "1.5e"},
+		{token.MINUS, "-"},
+		{token.INT, "3"},
+		{token.EOF, ""},
+	}
+
+	lex := New(input)
+	evaulateTestcases(lex, tests, t)
+
+	expected := []string{
+		"1:2: invalid character 'e' in decimal literal",
+		"1:10: exponent has no digits",
+	}
+	errors := lex.Errors()
+	if len(errors) != len(expected) {
+		t.Fatalf("lexer has wrong number of errors. expected=%d, got=%d (%v)", len(expected), len(errors), errors)
+	}
+	for i, msg := range expected {
+		if errors[i].Error() != msg {
+			t.Errorf("errors[%d] wrong. expected=%q, got=%q", i, msg, errors[i].Error())
+		}
+	}
+}
+
+func TestFloatLiterals(t *testing.T) {
+	input := "3.14 0.5 1e10 2.5E-3 6e+2 1_000.000_1 1.5.x 0x1e-2"
+
+	tests := []TokenTestcase{
+		{token.FLOAT, "3.14"},
+		{token.FLOAT, "0.5"},
+		{token.FLOAT, "1e10"},
+		{token.FLOAT, "2.5E-3"},
+		{token.FLOAT, "6e+2"},
+		{token.FLOAT, "1_000.000_1"},
+		{token.FLOAT, "1.5"},
+		{token.ILLEGAL, "."},
+		{token.IDENT, "x"},
+		{token.INT, "0x1e"},
+		{token.MINUS, "-"},
+		{token.INT, "2"},
+		{token.EOF, ""},
+	}
+
+	lex := New(input)
+	evaulateTestcases(lex, tests, t)
+}
+
+func TestMalformedFloatLiterals(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"1.5e", "1:4: exponent has no digits"},
+		{"1.5f", "1:4: invalid character 'f' in float literal"},
+		{"1.0_", "1:4: '_' must separate successive digits"},
+	}
+
+	for _, tt := range tests {
+		lex := New(tt.input)
+		tok := lex.NextToken()
+		if tok.Type != token.ILLEGAL || tok.Literal != tt.input {
+			t.Fatalf("%q - token wrong. expected ILLEGAL %q, got=%s %q", tt.input, tt.input, tok.Type, tok.Literal)
+		}
+		errors := lex.Errors()
+		if len(errors) != 1 || errors[0].Error() != tt.expectedError {
+			t.Errorf("%q - errors wrong. expected=%q, got=%v", tt.input, tt.expectedError, errors)
+		}
+	}
+}
+
+func TestBracketsAndColon(t *testing.T) {
+	input := `[1, 2][0] {"a": 1}`
+
+	tests := []TokenTestcase{
+		{token.LBRACKET, "["},
+		{token.INT, "1"},
+		{token.COMMA, ","},
+		{token.INT, "2"},
+		{token.RBRACKET, "]"},
+		{token.LBRACKET, "["},
+		{token.INT, "0"},
+		{token.RBRACKET, "]"},
+		{token.LBRACE, "{"},
+		{token.STRING, "a"},
+		{token.COLON, ":"},
+		{token.INT, "1"},
+		{token.RBRACE, "}"},
+		{token.EOF, ""},
+	}
+
+	lex := New(input)
+	evaulateTestcases(lex, tests, t)
+}
+
+func TestLogicalOperators(t *testing.T) {
+	input := `a && b || !c & d`
+
+	tests := []TokenTestcase{
+		{token.IDENT, "a"},
+		{token.AND, "&&"},
+		{token.IDENT, "b"},
+		{token.OR, "||"},
+		{token.BANG, "!"},
+		{token.IDENT, "c"},
+		{token.ILLEGAL, "&"},
+		{token.IDENT, "d"},
+		{token.EOF, ""},
+	}
+
+	lex := New(input)
+	evaulateTestcases(lex, tests, t)
+
+	errors := lex.Errors()
+	if len(errors) != 1 || errors[0].Error() != `1:14: illegal character '&', did you mean "&&"?` {
+		t.Errorf("errors wrong. got=%v", errors)
+	}
+}
@@ -13,6 +13,6 @@ func main() {
 		panic(err)
 	}
 	fmt.Printf("Hello %s! This is the Monkey Programming Language!\n", user.Username)
-	fmt.Printf("Feel free to type in commands\n")
+	fmt.Printf("Feel free to type in commands, or :help for the REPL commands\n")
 	repl.Start(os.Stdin, os.Stdout)
 }
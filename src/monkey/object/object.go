@@ -0,0 +1,268 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/big"
+	"monkey/ast"
+	"monkey/token"
+	"strconv"
+	"strings"
+)
+
+type ObjectType string
+
+const (
+	INTEGER_OBJ      = "INTEGER"
+	FLOAT_OBJ        = "FLOAT"
+	BOOLEAN_OBJ      = "BOOLEAN"
+	STRING_OBJ       = "STRING"
+	NULL_OBJ         = "NULL"
+	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	ERROR_OBJ        = "ERROR"
+	FUNCTION_OBJ     = "FUNCTION"
+	BUILTIN_OBJ      = "BUILTIN"
+	ARRAY_OBJ        = "ARRAY"
+	HASH_OBJ         = "HASH"
+)
+
+// Generic Object Section
+
+type Object interface {
+	Type() ObjectType
+	Inspect() string
+}
+
+// Hashable objects can be used as hash keys
+type Hashable interface {
+	HashKey() HashKey
+}
+
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+}
+
+// bigIntegerKey tags the keys of integers too large for an int64. Their
+// Value is a hash of the digits, which must not meet the small integers
+// keyed by the value itself.
+const bigIntegerKey ObjectType = "BIG_INTEGER"
+
+// Value Objects Section
+
+// Integer keeps values that fit in an int64 in Value; larger ones are kept
+// exactly in Big, which is nil otherwise
+type Integer struct {
+	Value int64
+	Big   *big.Int
+}
+
+func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
+func (i *Integer) Inspect() string {
+	if i.Big != nil {
+		return i.Big.String()
+	}
+	return strconv.FormatInt(i.Value, 10)
+}
+func (i *Integer) HashKey() HashKey {
+	if i.Big != nil {
+		return HashKey{Type: bigIntegerKey, Value: hashString(i.Big.String())}
+	}
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
+// IsBig reports whether the value does not fit in an int64
+func (i *Integer) IsBig() bool { return i.Big != nil }
+
+// BigValue returns the value as a big.Int the caller may modify
+func (i *Integer) BigValue() *big.Int {
+	if i.Big != nil {
+		return new(big.Int).Set(i.Big)
+	}
+	return big.NewInt(i.Value)
+}
+
+// NewBigInteger builds an Integer from a big.Int, falling back to the int64
+// representation when the value fits
+func NewBigInteger(value *big.Int) *Integer {
+	if value.IsInt64() {
+		return &Integer{Value: value.Int64()}
+	}
+	return &Integer{Big: value}
+}
+
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+func (f *Float) Inspect() string {
+	inspected := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	// Keep floats distinguishable from integers, e.g. 2.0 instead of 2
+	if !math.IsInf(f.Value, 0) && !math.IsNaN(f.Value) && !strings.ContainsAny(inspected, ".e") {
+		inspected += ".0"
+	}
+	return inspected
+}
+
+type Boolean struct {
+	Value bool
+}
+
+func (b *Boolean) Type() ObjectType { return BOOLEAN_OBJ }
+func (b *Boolean) Inspect() string  { return fmt.Sprintf("%t", b.Value) }
+func (b *Boolean) HashKey() HashKey {
+	var value uint64
+	if b.Value {
+		value = 1
+	}
+	return HashKey{Type: b.Type(), Value: value}
+}
+
+type String struct {
+	Value string
+}
+
+func (s *String) Type() ObjectType { return STRING_OBJ }
+func (s *String) Inspect() string {
+	return (&ast.StringLiteral{Value: s.Value}).String()
+}
+func (s *String) HashKey() HashKey {
+	return HashKey{Type: s.Type(), Value: hashString(s.Value)}
+}
+
+type Null struct{}
+
+func (n *Null) Type() ObjectType { return NULL_OBJ }
+func (n *Null) Inspect() string  { return "null" }
+
+// Control Flow Objects Section
+
+type ReturnValue struct {
+	Value Object
+}
+
+func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
+func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
+
+// Error is a runtime error; Pos locates the node that raised it
+type Error struct {
+	Message string
+	Pos     token.Position
+}
+
+func (e *Error) Type() ObjectType { return ERROR_OBJ }
+func (e *Error) Inspect() string {
+	if e.Pos.Line > 0 {
+		return fmt.Sprintf("ERROR: %s: %s", e.Pos, e.Message)
+	}
+	return "ERROR: " + e.Message
+}
+
+// Callable Objects Section
+
+type Function struct {
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	Env        *Environment
+}
+
+func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
+func (f *Function) Inspect() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range f.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString("fn(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") ")
+	out.WriteString(f.Body.String())
+
+	return out.String()
+}
+
+type BuiltinFunction func(args ...Object) Object
+
+type Builtin struct {
+	Name string
+	Fn   BuiltinFunction
+}
+
+func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
+func (b *Builtin) Inspect() string  { return "builtin function " + b.Name }
+
+// Collection Objects Section
+
+type Array struct {
+	Elements []Object
+}
+
+func (a *Array) Type() ObjectType { return ARRAY_OBJ }
+func (a *Array) Inspect() string {
+	var out bytes.Buffer
+
+	elements := []string{}
+	for _, e := range a.Elements {
+		elements = append(elements, e.Inspect())
+	}
+
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// Hash remembers the order keys were first inserted in, so Inspect is stable
+type Hash struct {
+	Pairs map[HashKey]HashPair
+	Keys  []HashKey
+}
+
+func NewHash() *Hash {
+	return &Hash{Pairs: map[HashKey]HashPair{}, Keys: []HashKey{}}
+}
+
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+func (h *Hash) Inspect() string {
+	var out bytes.Buffer
+
+	pairs := []string{}
+	for _, key := range h.Keys {
+		pair := h.Pairs[key]
+		pairs = append(pairs, pair.Key.Inspect()+": "+pair.Value.Inspect())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// Set stores the pair, keeping the position of a key that already exists
+func (h *Hash) Set(key Hashable, pair HashPair) {
+	hashKey := key.HashKey()
+	if _, ok := h.Pairs[hashKey]; !ok {
+		h.Keys = append(h.Keys, hashKey)
+	}
+	h.Pairs[hashKey] = pair
+}
+
+// Utilities
+
+func hashString(value string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(value))
+	return h.Sum64()
+}
@@ -0,0 +1,77 @@
+package object
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestStringHashKey(t *testing.T) {
+	hello1 := &String{Value: "Hello World"}
+	hello2 := &String{Value: "Hello World"}
+	diff1 := &String{Value: "My name is johnny"}
+	diff2 := &String{Value: "My name is johnny"}
+
+	if hello1.HashKey() != hello2.HashKey() {
+		t.Errorf("strings with same content have different hash keys")
+	}
+	if diff1.HashKey() != diff2.HashKey() {
+		t.Errorf("strings with same content have different hash keys")
+	}
+	if hello1.HashKey() == diff1.HashKey() {
+		t.Errorf("strings with different content have same hash keys")
+	}
+}
+
+func TestIntegerHashKey(t *testing.T) {
+	small := &Integer{Value: 1}
+	fitting := NewBigInteger(big.NewInt(1))
+	huge, _ := new(big.Int).SetString("99999999999999999999", 10)
+
+	if small.HashKey() != fitting.HashKey() {
+		t.Errorf("integers with same value have different hash keys")
+	}
+	if NewBigInteger(huge).HashKey() != NewBigInteger(new(big.Int).Set(huge)).HashKey() {
+		t.Errorf("big integers with same value have different hash keys")
+	}
+	if small.HashKey() == (&Boolean{Value: true}).HashKey() {
+		t.Errorf("integer and boolean share a hash key")
+	}
+
+	// The small value is the hash of the big one's digits
+	above, _ := new(big.Int).SetString("9223372036854775808", 10)
+	if NewBigInteger(above).HashKey() == (&Integer{Value: -590260884831411150}).HashKey() {
+		t.Errorf("big and small integers share a hash key")
+	}
+}
+
+func TestHashKeepsInsertionOrder(t *testing.T) {
+	hash := NewHash()
+	for i, key := range []string{"b", "a", "b", "c"} {
+		hashKey := &String{Value: key}
+		hash.Set(hashKey, HashPair{Key: hashKey, Value: &Integer{Value: int64(i)}})
+	}
+
+	expected := `{"b": 2, "a": 1, "c": 3}`
+	if hash.Inspect() != expected {
+		t.Errorf("hash.Inspect() wrong. expected=%s, got=%s", expected, hash.Inspect())
+	}
+}
+
+func TestEnvironmentAssign(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("x", &Integer{Value: 1})
+	inner := NewEnclosedEnvironment(outer)
+
+	if !inner.Assign("x", &Integer{Value: 2}) {
+		t.Fatalf("inner.Assign did not find x in the outer scope")
+	}
+	if obj, _ := outer.Get("x"); obj.Inspect() != "2" {
+		t.Errorf("outer x not reassigned. got=%s", obj.Inspect())
+	}
+	if inner.Assign("y", &Integer{Value: 3}) {
+		t.Errorf("inner.Assign bound an undefined name")
+	}
+	if names := inner.Names(); len(names) != 0 {
+		t.Errorf("inner.Names() should be empty. got=%v", names)
+	}
+}
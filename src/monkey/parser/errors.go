@@ -0,0 +1,154 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"monkey/token"
+	"sort"
+	"strings"
+)
+
+// Error codes, stable across releases so tools can match on them
+
+type ErrorCode string
+
+const (
+	ErrUnexpectedToken ErrorCode = "E001"
+	ErrNoPrefixParseFn ErrorCode = "E002"
+	ErrInvalidInteger  ErrorCode = "E003"
+	ErrUnclosedBlock   ErrorCode = "E004"
+	ErrUnclosedGroup   ErrorCode = "E005"
+	ErrBooleanAlias    ErrorCode = "E006"
+	ErrTooManyErrors   ErrorCode = "E007" // No longer reported; see Parser.Halted
+	ErrIllegalToken    ErrorCode = "E008"
+	ErrInvalidFloat    ErrorCode = "E009"
+	ErrUnclosedBracket ErrorCode = "E010"
+	ErrUnclosedHash    ErrorCode = "E011"
+	ErrUnclosedComment ErrorCode = "E012"
+)
+
+// Error is a single positioned parser diagnostic. Expected is empty when the
+// diagnostic is not about a missing token.
+type Error struct {
+	Pos      token.Position
+	End      token.Position
+	Code     ErrorCode
+	Expected token.TokenType
+	Actual   token.TokenType
+	Msg      string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// Render prints the diagnostic followed by the offending source line with
+// the token underlined by carets
+func (e *Error) Render(source string) string {
+	var out bytes.Buffer
+
+	out.WriteString(fmt.Sprintf("%s: error[%s]: %s\n", e.Pos, e.Code, e.Msg))
+
+	offset := e.Pos.Offset
+	if offset > len(source) {
+		offset = len(source)
+	}
+	lineStart := strings.LastIndexByte(source[:offset], '\n') + 1
+	lineEnd := strings.IndexAny(source[offset:], "\r\n")
+	if lineEnd < 0 {
+		lineEnd = len(source)
+	} else {
+		lineEnd += offset
+	}
+
+	out.WriteString(source[lineStart:lineEnd])
+	out.WriteString("\n")
+
+	// Keep tabs so the caret lines up with the source as displayed
+	for _, ch := range source[lineStart:offset] {
+		if ch == '\t' {
+			out.WriteString("\t")
+		} else {
+			out.WriteString(" ")
+		}
+	}
+
+	width := 1
+	if e.End.Line == e.Pos.Line && e.End.Offset > e.Pos.Offset {
+		end := e.End.Offset
+		if end > lineEnd {
+			end = lineEnd
+		}
+		if w := len([]rune(source[offset:end])); w > 1 {
+			width = w
+		}
+	}
+	out.WriteString(strings.Repeat("^", width))
+
+	return out.String()
+}
+
+// ErrorList is a list of parser diagnostics in the order they were reported
+
+type ErrorList []*Error
+
+func (list ErrorList) Len() int      { return len(list) }
+func (list ErrorList) Swap(i, j int) { list[i], list[j] = list[j], list[i] }
+func (list ErrorList) Less(i, j int) bool {
+	a, b := list[i], list[j]
+	if a.Pos.Offset != b.Pos.Offset {
+		return a.Pos.Offset < b.Pos.Offset
+	}
+	if a.Code != b.Code {
+		return a.Code < b.Code
+	}
+	return a.Msg < b.Msg
+}
+
+// Sort orders the list by source position
+func (list ErrorList) Sort() {
+	sort.Sort(list)
+}
+
+// RemoveMultiples sorts the list and drops diagnostics repeated at the
+// same position with the same message
+func (list *ErrorList) RemoveMultiples() {
+	list.Sort()
+	var last *Error
+	i := 0
+	for _, e := range *list {
+		if last == nil || e.Pos != last.Pos || e.Msg != last.Msg {
+			last = e
+			(*list)[i] = e
+			i++
+		}
+	}
+	*list = (*list)[0:i]
+}
+
+func (list ErrorList) Error() string {
+	switch len(list) {
+	case 0:
+		return "no errors"
+	case 1:
+		return list[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", list[0], len(list)-1)
+}
+
+// Err returns the list as an error, or nil when it is empty
+func (list ErrorList) Err() error {
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
+
+// Render prints every diagnostic in the list against the source
+func (list ErrorList) Render(source string) string {
+	rendered := []string{}
+	for _, e := range list {
+		rendered = append(rendered, e.Render(source))
+	}
+	return strings.Join(rendered, "\n")
+}
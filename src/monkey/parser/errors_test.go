@@ -0,0 +1,266 @@
+package parser
+
+import (
+	"monkey/lexer"
+	"monkey/token"
+	"testing"
+)
+
+func TestErrorFields(t *testing.T) {
+	input := "let x 5;"
+	testParser := New(lexer.New(input))
+	testParser.ParseProgram()
+
+	errors := testParser.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("parser reported no errors")
+	}
+	err := errors[0]
+	if err.Code != ErrUnexpectedToken {
+		t.Errorf("err.Code wrong. expected=%s, got=%s", ErrUnexpectedToken, err.Code)
+	}
+	if err.Expected != token.ASSIGN || err.Actual != token.INT {
+		t.Errorf("err.Expected/Actual wrong. got=%s/%s", err.Expected, err.Actual)
+	}
+	if err.Pos.String() != "1:7" {
+		t.Errorf("err.Pos wrong. expected=1:7, got=%s", err.Pos)
+	}
+	expected := "1:7: expected next token to be =, got INT instead"
+	if err.Error() != expected {
+		t.Errorf("err.Error() wrong. expected=%q, got=%q", expected, err.Error())
+	}
+}
+
+func TestErrorRender(t *testing.T) {
+	input := "let a = 1;\n\tlet b 200;\nb"
+	testParser := New(lexer.New(input))
+	testParser.ParseProgram()
+
+	errors := testParser.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("parser reported no errors")
+	}
+	expected := "2:8: error[E001]: expected next token to be =, got INT instead\n" +
+		"\tlet b 200;\n" +
+		"\t      ^^^"
+	if errors[0].Render(input) != expected {
+		t.Errorf("Render() wrong.\nexpected=\n%s\ngot=\n%s", expected, errors[0].Render(input))
+	}
+}
+
+func TestErrorListSortAndRemoveMultiples(t *testing.T) {
+	at := func(offset int, msg string) *Error {
+		return &Error{Pos: token.Position{Offset: offset, Line: 1, Column: offset + 1}, Msg: msg}
+	}
+	list := ErrorList{at(5, "b"), at(1, "a"), at(5, "b"), at(5, "a"), at(1, "a")}
+
+	list.RemoveMultiples()
+
+	expected := []string{"1:2: a", "1:6: a", "1:6: b"}
+	if len(list) != len(expected) {
+		t.Fatalf("list has wrong length. expected=%d, got=%d (%v)", len(expected), len(list), list)
+	}
+	for i, e := range list {
+		if e.Error() != expected[i] {
+			t.Errorf("list[%d] wrong. expected=%q, got=%q", i, expected[i], e.Error())
+		}
+	}
+	if (ErrorList{}).Err() != nil {
+		t.Errorf("empty ErrorList.Err() is not nil")
+	}
+}
+
+func TestErrorRecoveryReportsEachMistakeOnce(t *testing.T) {
+	input := `
+	let x 5;
+	let = 10;
+	let y = 3;
+	return );
+	let z = fn(a) { let b = ; a }(1);
+	`
+	testParser := New(lexer.New(input))
+	program := testParser.ParseProgram()
+
+	errors := testParser.Errors()
+	expected := []string{
+		"2:8: expected next token to be =, got INT instead",
+		"3:6: expected next token to be IDENT, got = instead",
+		"5:9: no prefix parse function for ) found",
+		"6:26: no prefix parse function for ; found",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("parser has wrong number of errors. expected=%d, got=%d (%q)", len(expected), len(errors), errors)
+	}
+	for i, msg := range expected {
+		if errors[i].Error() != msg {
+			t.Errorf("errors[%d] wrong. expected=%q, got=%q", i, msg, errors[i].Error())
+		}
+	}
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d (%s)", len(program.Statements), program)
+	}
+	if program.String() != "let y = 3;let z = fn(a) { a; }(1);" {
+		t.Errorf("recovered program wrong. got=%q", program.String())
+	}
+}
+
+func TestRecoveryKeepsClosingBrace(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		errorPos string
+	}{
+		{"fn(a) { a + }; let x = 1; x", "fn(a) { }let x = 1;x", "1:13"},
+		{"if (x) { 1 + } let y = 2; y", "if (x) { }let y = 2;y", "1:14"},
+		{"let f = fn() { let b = 1; b * }; f", "let f = fn() { let b = 1; };f", "1:31"},
+		{"if (x) { if (y) { 1 + } } let y = 2; y", "if (x) { if (y) { }; }let y = 2;y", "1:23"},
+	}
+
+	for _, tt := range tests {
+		testParser := New(lexer.New(tt.input))
+		program := testParser.ParseProgram()
+
+		errors := testParser.Errors()
+		if len(errors) != 1 {
+			t.Errorf("%q - parser has wrong number of errors. expected=1, got=%d (%q)", tt.input, len(errors), errors)
+			continue
+		}
+		if errors[0].Pos.String() != tt.errorPos {
+			t.Errorf("%q - error at wrong position. expected=%s, got=%s", tt.input, tt.errorPos, errors[0].Pos)
+		}
+		if program.String() != tt.expected {
+			t.Errorf("%q - recovered program wrong. expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestRecoveryInsideHashLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		errorPos string
+	}{
+		{`{"a": 1 + }; let z = 3; z`, "let z = 3;z", "1:11"},
+		{`let h = {"a": }; let w = 4; w`, "let w = 4;w", "1:15"},
+		{`fn() { {"a": 1 + } }; let z = 3; z`, "fn() { }let z = 3;z", "1:18"},
+		{`fn() { let h = {"a": 1 +}; h }; 5`, "fn() { h; }5", "1:25"},
+		{`if (x) { let h = {"a": {"b": }}; 1 } else { 2 }; 3`, "if (x) { 1; } else { 2; }3", "1:30"},
+	}
+
+	for _, tt := range tests {
+		testParser := New(lexer.New(tt.input))
+		program := testParser.ParseProgram()
+
+		errors := testParser.Errors()
+		if len(errors) != 1 {
+			t.Errorf("%q - parser has wrong number of errors. expected=1, got=%d (%q)", tt.input, len(errors), errors)
+			continue
+		}
+		if errors[0].Pos.String() != tt.errorPos {
+			t.Errorf("%q - error at wrong position. expected=%s, got=%s", tt.input, tt.errorPos, errors[0].Pos)
+		}
+		if program.String() != tt.expected {
+			t.Errorf("%q - recovered program wrong. expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestThreeIndependentErrors(t *testing.T) {
+	input := "let a 1; let b = 2; if (b { b }; let c = 3; b + * c; c"
+	testParser := New(lexer.New(input))
+	program := testParser.ParseProgram()
+
+	if len(testParser.Errors()) != 3 {
+		t.Fatalf("parser has wrong number of errors. expected=3, got=%d (%q)", len(testParser.Errors()), testParser.Errors())
+	}
+	if program.String() != "let b = 2;let c = 3;c" {
+		t.Errorf("recovered program wrong. got=%q", program.String())
+	}
+}
+
+func TestMaxErrors(t *testing.T) {
+	input := "let 1; let 2; let 3; let 4;"
+	testParser := New(lexer.New(input), WithMaxErrors(2))
+	testParser.ParseProgram()
+
+	errors := testParser.Errors()
+	if len(errors) != 2 {
+		t.Fatalf("parser has wrong number of errors. expected=2, got=%d (%q)", len(errors), errors)
+	}
+	if errors[1].Error() != "1:12: expected next token to be IDENT, got INT instead" {
+		t.Errorf("last error wrong. got=%q", errors[1].Error())
+	}
+	if !testParser.Halted() {
+		t.Errorf("parser not halted at the error limit")
+	}
+
+	tests := []struct {
+		limit          int
+		expectedErrors int
+		expectedHalted bool
+	}{
+		{1, 1, true},
+		{4, 4, true},
+		{5, 4, false},
+		{0, 4, false},
+	}
+
+	for _, tt := range tests {
+		testParser := New(lexer.New(input), WithMaxErrors(tt.limit))
+		testParser.ParseProgram()
+
+		if len(testParser.Errors()) != tt.expectedErrors {
+			t.Errorf("limit %d - wrong number of errors. expected=%d, got=%d", tt.limit, tt.expectedErrors, len(testParser.Errors()))
+		}
+		if testParser.Halted() != tt.expectedHalted {
+			t.Errorf("limit %d - halted wrong. expected=%t, got=%t", tt.limit, tt.expectedHalted, testParser.Halted())
+		}
+	}
+}
+
+func TestLexerErrorsAreReported(t *testing.T) {
+	testParser := New(lexer.New("let x = 1 + \xfe;\nlet y = @;"))
+	testParser.ParseProgram()
+
+	expected := []string{
+		`1:13: invalid UTF-8 encoding "\xfe"`,
+		`2:9: illegal character '@'`,
+	}
+	errors := testParser.Errors()
+	if len(errors) != len(expected) {
+		t.Fatalf("parser has wrong number of errors. expected=%d, got=%d (%q)", len(expected), len(errors), errors)
+	}
+	for i, msg := range expected {
+		if errors[i].Error() != msg || errors[i].Code != ErrIllegalToken {
+			t.Errorf("errors[%d] wrong. expected=%q, got=%q (%s)", i, msg, errors[i].Error(), errors[i].Code)
+		}
+	}
+}
+
+func TestUnclosedCommentError(t *testing.T) {
+	testParser := New(lexer.New("let x = 1;\n/* open /* nested */"))
+	testParser.ParseProgram()
+
+	errors := testParser.Errors()
+	if len(errors) != 1 {
+		t.Fatalf("parser has wrong number of errors. expected=1, got=%d (%q)", len(errors), errors)
+	}
+	if errors[0].Error() != "2:1: unterminated block comment" || errors[0].Code != ErrUnclosedComment {
+		t.Errorf("error wrong. got=%q (%s)", errors[0].Error(), errors[0].Code)
+	}
+}
+
+func TestStringErrorsPointAtEscape(t *testing.T) {
+	testParser := New(lexer.New(`let s = "ab\xcd";`))
+	testParser.ParseProgram()
+
+	errors := testParser.Errors()
+	if len(errors) != 1 {
+		t.Fatalf("parser has wrong number of errors. expected=1, got=%d (%q)", len(errors), errors)
+	}
+	expected := `1:12: unknown escape sequence \x`
+	if errors[0].Error() != expected {
+		t.Errorf("wrong error. expected=%q, got=%q", expected, errors[0].Error())
+	}
+}
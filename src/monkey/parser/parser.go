@@ -1,16 +1,21 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
 	"monkey/ast"
 	"monkey/lexer"
 	"monkey/token"
 	"strconv"
+	"strings"
 )
 
 const (
 	_ int = iota
 	LOWEST
+	OR
+	AND
 	EQUALS
 	LGEQUALS
 	LG
@@ -18,16 +23,77 @@ const (
 	PRODUCT
 	PREFIX
 	CALL
+	INDEX
 )
 
+var assignOperators = map[token.TokenType]bool{
+	token.ASSIGN:      true,
+	token.ADDASSIGN:   true,
+	token.MINUSASSIGN: true,
+	token.MULASSIGN:   true,
+	token.DIVASSIGN:   true,
+}
+
+var precedences = map[token.TokenType]int{
+	token.OR:       OR,
+	token.AND:      AND,
+	token.EQ:       EQUALS,
+	token.NEQ:      EQUALS,
+	token.LE:       LGEQUALS,
+	token.GE:       LGEQUALS,
+	token.LT:       LG,
+	token.GT:       LG,
+	token.PLUS:     SUM,
+	token.MINUS:    SUM,
+	token.ASTERISK: PRODUCT,
+	token.SLASH:    PRODUCT,
+	token.LPAREN:   CALL,
+	token.LBRACKET: INDEX,
+}
+
 type (
 	prefixParseFn func() ast.Expression
 	infixParseFn  func(ast.Expression) ast.Expression
 )
 
+// Handling of the capitalized True/False boolean aliases
+
+type BooleanAliasMode int
+
+const (
+	AllowBooleanAliases BooleanAliasMode = iota
+	WarnBooleanAliases
+	RejectBooleanAliases
+)
+
+type Option func(*Parser)
+
+// WithMaxErrors stops parsing once limit errors have been reported, so
+// Errors never holds more than limit; Halted tells whether parsing stopped
+// early. A limit of 0 or less reports every error.
+func WithMaxErrors(limit int) Option {
+	return func(p *Parser) {
+		p.maxErrors = limit
+	}
+}
+
+func WithBooleanAliases(mode BooleanAliasMode) Option {
+	return func(p *Parser) {
+		p.booleanAliasMode = mode
+	}
+}
+
 type Parser struct {
-	lex    *lexer.Lexer
-	errors []string
+	lex      *lexer.Lexer
+	errors   ErrorList
+	warnings ErrorList
+
+	booleanAliasMode BooleanAliasMode
+	maxErrors        int
+
+	panicking  bool // An error was reported and the statement is not yet synchronized
+	braceDepth int  // Braces opened and not yet closed up to the current token
+	halted     bool // The error limit was reached
 
 	curToken  token.Token
 	peekToken token.Token
@@ -46,14 +112,38 @@ func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
 
 // Initializer
 
-func New(lex *lexer.Lexer) *Parser {
-	instance := &Parser{lex: lex, errors: []string{}}
+func New(lex *lexer.Lexer, options ...Option) *Parser {
+	instance := &Parser{lex: lex, errors: ErrorList{}, warnings: ErrorList{}}
+	for _, option := range options {
+		option(instance)
+	}
 	instance.nextToken()
 	instance.nextToken()
 
 	instance.prefixParseFns = make(map[token.TokenType]prefixParseFn)
+	instance.registerPrefix(token.ILLEGAL, instance.parseIllegal)
 	instance.registerPrefix(token.IDENT, instance.parseIdentifier)
 	instance.registerPrefix(token.INT, instance.parseIntegerLiteral)
+	instance.registerPrefix(token.FLOAT, instance.parseFloatLiteral)
+	instance.registerPrefix(token.STRING, instance.parseStringLiteral)
+	instance.registerPrefix(token.TRUE, instance.parseBoolean)
+	instance.registerPrefix(token.FALSE, instance.parseBoolean)
+	instance.registerPrefix(token.LPAREN, instance.parseGroupedExpression)
+	instance.registerPrefix(token.LBRACKET, instance.parseArrayLiteral)
+	instance.registerPrefix(token.LBRACE, instance.parseHashLiteral)
+	instance.registerPrefix(token.IF, instance.parseIfExpression)
+	instance.registerPrefix(token.FUNCTION, instance.parseFunctionLiteral)
+	instance.registerPrefix(token.BANG, instance.parsePrefixExpression)
+	instance.registerPrefix(token.MINUS, instance.parsePrefixExpression)
+
+	instance.infixParseFns = make(map[token.TokenType]infixParseFn)
+	for tokenType := range precedences {
+		instance.registerInfix(tokenType, instance.parseInfixExpression)
+	}
+	instance.registerInfix(token.AND, instance.parseLogicalExpression)
+	instance.registerInfix(token.OR, instance.parseLogicalExpression)
+	instance.registerInfix(token.LPAREN, instance.parseCallExpression)
+	instance.registerInfix(token.LBRACKET, instance.parseIndexExpression)
 	return instance
 }
 
@@ -63,8 +153,8 @@ func (p *Parser) ParseProgram() *ast.Program {
 	program := &ast.Program{}
 	program.Statements = []ast.Statement{}
 
-	for !p.curTokenIs(token.EOF) {
-		stmt := p.parseStatement()
+	for !p.curTokenIs(token.EOF) && !p.halted {
+		stmt := p.parseStatementWithRecovery()
 		if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
@@ -76,12 +166,36 @@ func (p *Parser) ParseProgram() *ast.Program {
 
 // Parse Statement
 
+// parseStatementWithRecovery drops a statement that reported an error and
+// skips ahead to the next statement boundary
+func (p *Parser) parseStatementWithRecovery() ast.Statement {
+	// depth is the nesting outside the statement. A statement that starts
+	// with a hash literal has already counted its own opening brace, and
+	// the brace closing the enclosing block takes braceDepth below depth.
+	depth := p.braceDepth
+	if p.curTokenIs(token.LBRACE) {
+		depth -= 1
+	}
+
+	stmt := p.parseStatement()
+	if p.panicking {
+		p.synchronize(depth)
+		return nil
+	}
+	return stmt
+}
+
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.curToken.Type {
 	case token.LET:
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.IDENT:
+		if assignOperators[p.peekToken.Type] {
+			return p.parseAssignStatement()
+		}
+		return p.parseExpressionStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -100,19 +214,42 @@ func (p *Parser) parseLetStatement() ast.Statement {
 		return nil
 	}
 
-	for !p.curTokenIs(token.SEMICOLON) {
-		p.nextToken()
-	}
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	p.skipSemicolon()
 
 	return stmt
 }
 
 func (p *Parser) parseReturnStatement() ast.Statement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
-	p.nextToken()
-	for !p.curTokenIs(token.SEMICOLON) {
-		p.nextToken()
+
+	// A bare `return` carries no value
+	if p.peekTokenIs(token.SEMICOLON) || p.peekTokenIs(token.RBRACE) || p.peekTokenIs(token.EOF) {
+		p.skipSemicolon()
+		return stmt
 	}
+
+	p.nextToken()
+	stmt.ReturnValue = p.parseExpression(LOWEST)
+
+	p.skipSemicolon()
+
+	return stmt
+}
+
+func (p *Parser) parseAssignStatement() ast.Statement {
+	name := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	p.nextToken()
+	stmt := &ast.AssignStatement{Token: p.curToken, Name: name, Operator: p.curToken.Literal}
+
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	p.skipSemicolon()
+
 	return stmt
 }
 
@@ -120,25 +257,80 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	stmt := &ast.ExpressionStatement{Token: p.curToken}
 	stmt.Expression = p.parseExpression(LOWEST)
 
-	if p.peekTokenIs(token.SEMICOLON) {
+	p.skipSemicolon()
+
+	return stmt
+}
+
+func (p *Parser) parseBlockStatement() *ast.BlockStatement {
+	block := &ast.BlockStatement{Token: p.curToken}
+	block.Statements = []ast.Statement{}
+
+	depth := p.braceDepth
+	p.nextToken()
+
+	for !p.curTokenIs(token.RBRACE) && !p.halted {
+		if p.curTokenIs(token.EOF) {
+			msg := fmt.Sprintf("expected %s to close block, got %s instead", token.RBRACE, token.EOF)
+			p.addError(p.curToken, ErrUnclosedBlock, token.RBRACE, msg)
+			break
+		}
+		stmt := p.parseStatementWithRecovery()
+		if stmt != nil {
+			block.Statements = append(block.Statements, stmt)
+		}
+		// A broken statement can stop on this block's own closing brace
+		if p.curTokenIs(token.RBRACE) && p.braceDepth < depth {
+			break
+		}
 		p.nextToken()
 	}
+	if p.curTokenIs(token.RBRACE) {
+		block.Rbrace = p.curToken
+	}
 
-	return stmt
+	return block
 }
 
 func (p *Parser) parseExpression(precedence int) ast.Expression {
 	prefix := p.prefixParseFns[p.curToken.Type]
 	if prefix == nil {
+		p.noPrefixParseFnError(p.curToken.Type)
 		return nil
 	}
 	leftExp := prefix()
+
+	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
+		infix := p.infixParseFns[p.peekToken.Type]
+		if infix == nil {
+			return leftExp
+		}
+		p.nextToken()
+		leftExp = infix(leftExp)
+	}
+
 	return leftExp
 }
 
 // Expression Parsing Functions
 // Prefix Functions
 
+// parseIllegal surfaces the lexer's diagnostic for an ILLEGAL token
+func (p *Parser) parseIllegal() ast.Expression {
+	code := ErrIllegalToken
+	msg := fmt.Sprintf("illegal token %q", p.curToken.Literal)
+	tok := p.curToken
+	if lexError, ok := p.lex.ErrorFor(tok); ok {
+		msg = lexError.Msg
+		tok.Pos = lexError.Pos
+		if lexError.Unclosed {
+			code = ErrUnclosedComment
+		}
+	}
+	p.addError(tok, code, "", msg)
+	return nil
+}
+
 func (p *Parser) parseIdentifier() ast.Expression {
 	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 }
@@ -146,21 +338,367 @@ func (p *Parser) parseIdentifier() ast.Expression {
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 
-	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	value, err := parseInteger(p.curToken.Literal)
+	if errors.Is(err, strconv.ErrRange) {
+		// Too large for int64, fall back to an exact big integer
+		if n, ok := parseBigInteger(p.curToken.Literal); ok {
+			lit.Big = n
+			return lit
+		}
+	}
 	if err != nil {
 		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.addError(p.curToken, ErrInvalidInteger, "", msg)
+		return nil
+	}
+	lit.Value = value
+	return lit
+}
+
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	array := &ast.ArrayLiteral{Token: p.curToken}
+
+	array.Elements = p.parseExpressionList(ErrUnclosedBracket, token.RBRACKET, "array literal")
+	if array.Elements == nil {
+		return nil
+	}
+	array.Rbracket = p.curToken
+
+	return array
+}
+
+// parseHashLiteral handles `{` in expression position. Blocks only follow
+// `if`, `else` and `fn`, which parse them directly, so a brace reached here
+// always opens a hash.
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.curToken}
+	hash.Pairs = []ast.HashPair{}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		key := p.parseExpression(LOWEST)
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+		value := p.parseExpression(LOWEST)
+
+		hash.Pairs = append(hash.Pairs, ast.HashPair{Key: key, Value: value})
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.peekTokenIs(token.RBRACE) {
+		p.unclosedError(ErrUnclosedHash, hash.Token, token.RBRACE, "hash literal")
+		return nil
+	}
+	p.nextToken()
+	hash.Rbrace = p.curToken
+
+	return hash
+}
+
+func (p *Parser) parseStringLiteral() ast.Expression {
+	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.curToken}
+
+	value, err := strconv.ParseFloat(strings.ReplaceAll(p.curToken.Literal, "_", ""), 64)
+	if err == nil && value == 0 && underflows(p.curToken.Literal) {
+		err = strconv.ErrRange
+	}
+	if err != nil {
+		msg := fmt.Sprintf("could not parse %q as float", p.curToken.Literal)
+		if errors.Is(err, strconv.ErrRange) {
+			msg = fmt.Sprintf("float literal %q is out of range", p.curToken.Literal)
+		}
+		p.addError(p.curToken, ErrInvalidFloat, "", msg)
 		return nil
 	}
 	lit.Value = value
 	return lit
 }
 
+// underflows reports whether a float literal that parsed as zero has a
+// non-zero mantissa, meaning it is too small to represent
+func underflows(literal string) bool {
+	mantissa := literal
+	if i := strings.IndexAny(literal, "eE"); i >= 0 {
+		mantissa = literal[:i]
+	}
+	return strings.ContainsAny(mantissa, "123456789")
+}
+
+// parseInteger reads a literal the lexer accepted. A leading zero without
+// a 0x, 0o or 0b prefix does not make the literal octal.
+func parseInteger(literal string) (int64, error) {
+	if hasBasePrefix(literal) {
+		return strconv.ParseInt(literal, 0, 64)
+	}
+	return strconv.ParseInt(strings.ReplaceAll(literal, "_", ""), 10, 64)
+}
+
+func parseBigInteger(literal string) (*big.Int, bool) {
+	if hasBasePrefix(literal) {
+		return new(big.Int).SetString(literal, 0)
+	}
+	return new(big.Int).SetString(strings.ReplaceAll(literal, "_", ""), 10)
+}
+
+func hasBasePrefix(literal string) bool {
+	return len(literal) >= 2 && literal[0] == '0' && strings.ContainsRune("xXoObB", rune(literal[1]))
+}
+
+func (p *Parser) parseBoolean() ast.Expression {
+	literal := p.curToken.Literal
+	if literal == "True" || literal == "False" {
+		switch p.booleanAliasMode {
+		case WarnBooleanAliases:
+			msg := fmt.Sprintf("boolean alias %q used, prefer %q", literal, strings.ToLower(literal))
+			p.warnings = append(p.warnings, newError(p.curToken, ErrBooleanAlias, "", msg))
+		case RejectBooleanAliases:
+			msg := fmt.Sprintf("boolean alias %q is not allowed, use %q", literal, strings.ToLower(literal))
+			p.addError(p.curToken, ErrBooleanAlias, "", msg)
+			return nil
+		}
+	}
+	return &ast.Boolean{Token: p.curToken, Value: p.curTokenIs(token.TRUE)}
+}
+
+func (p *Parser) parseGroupedExpression() ast.Expression {
+	group := &ast.GroupedExpression{Token: p.curToken}
+	p.nextToken()
+
+	group.Expression = p.parseExpression(LOWEST)
+
+	if !p.peekTokenIs(token.RPAREN) {
+		p.unclosedError(ErrUnclosedGroup, group.Token, token.RPAREN, "grouped expression")
+		return nil
+	}
+	p.nextToken()
+	group.Rparen = p.curToken
+
+	if group.Expression == nil {
+		return nil
+	}
+	return group
+}
+
+func (p *Parser) parseIfExpression() ast.Expression {
+	expression := &ast.IfExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Consequence = p.parseBlockStatement()
+
+	if !p.peekTokenIs(token.ELSE) {
+		return expression
+	}
+	p.nextToken()
+
+	// `else if` is kept as an alternative block holding the nested if
+	if p.peekTokenIs(token.IF) {
+		p.nextToken()
+		alternative := &ast.BlockStatement{Token: p.curToken}
+		nested := &ast.ExpressionStatement{Token: p.curToken, Expression: p.parseIfExpression()}
+		if nested.Expression == nil {
+			return nil
+		}
+		alternative.Statements = []ast.Statement{nested}
+		expression.Alternative = alternative
+		return expression
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	expression.Alternative = p.parseBlockStatement()
+
+	return expression
+}
+
+func (p *Parser) parseFunctionLiteral() ast.Expression {
+	literal := &ast.FunctionLiteral{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	literal.Parameters = p.parseFunctionParameters()
+	if literal.Parameters == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	literal.Body = p.parseBlockStatement()
+
+	return literal
+}
+
+func (p *Parser) parseFunctionParameters() []*ast.Identifier {
+	identifiers := []*ast.Identifier{}
+
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return identifiers
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	identifiers = append(identifiers, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		identifiers = append(identifiers, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	return identifiers
+}
+
+func (p *Parser) parsePrefixExpression() ast.Expression {
+	expression := &ast.PrefixExpression{
+		Token:    p.curToken,
+		Operator: p.curToken.Literal,
+	}
+
+	p.nextToken()
+	expression.Right = p.parseExpression(PREFIX)
+
+	return expression
+}
+
+// Infix Functions
+
+func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
+	expression := &ast.InfixExpression{
+		Token:    p.curToken,
+		Operator: p.curToken.Literal,
+		Left:     left,
+	}
+
+	precedence := p.curPrecedence()
+	p.nextToken()
+	expression.Right = p.parseExpression(precedence)
+
+	return expression
+}
+
+func (p *Parser) parseLogicalExpression(left ast.Expression) ast.Expression {
+	expression := &ast.LogicalExpression{
+		Token:    p.curToken,
+		Operator: p.curToken.Literal,
+		Left:     left,
+	}
+
+	precedence := p.curPrecedence()
+	p.nextToken()
+	expression.Right = p.parseExpression(precedence)
+
+	return expression
+}
+
+func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
+	expression := &ast.CallExpression{Token: p.curToken, Function: function}
+	expression.Arguments = p.parseExpressionList(ErrUnclosedGroup, token.RPAREN, "call arguments")
+	if expression.Arguments == nil {
+		return nil
+	}
+	expression.Rparen = p.curToken
+	return expression
+}
+
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	expression := &ast.IndexExpression{Token: p.curToken, Left: left}
+
+	p.nextToken()
+	expression.Index = p.parseExpression(LOWEST)
+
+	if !p.peekTokenIs(token.RBRACKET) {
+		p.unclosedError(ErrUnclosedBracket, expression.Token, token.RBRACKET, "index expression")
+		return nil
+	}
+	p.nextToken()
+	expression.Rbracket = p.curToken
+
+	return expression
+}
+
+// parseExpressionList parses comma separated expressions up to the closing
+// token, allowing a trailing comma, and leaves the parser on the closing token
+func (p *Parser) parseExpressionList(code ErrorCode, closing token.TokenType, construct string) []ast.Expression {
+	opening := p.curToken
+	list := []ast.Expression{}
+
+	for !p.peekTokenIs(closing) {
+		p.nextToken()
+		list = append(list, p.parseExpression(LOWEST))
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.peekTokenIs(closing) {
+		p.unclosedError(code, opening, closing, construct)
+		return nil
+	}
+	p.nextToken()
+
+	return list
+}
+
 // Utilities
 
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
 	p.peekToken = p.lex.NextToken()
+
+	switch p.curToken.Type {
+	case token.LBRACE:
+		p.braceDepth += 1
+	case token.RBRACE:
+		p.braceDepth -= 1
+	}
+}
+
+// skipSemicolon consumes the optional `;` after a statement. A statement
+// that failed leaves it for synchronize, so that an error just before a
+// closing brace does not move the parser past the brace.
+func (p *Parser) skipSemicolon() {
+	if p.peekTokenIs(token.SEMICOLON) && !p.panicking {
+		p.nextToken()
+	}
 }
 
 func (p *Parser) curTokenIs(t token.TokenType) bool {
@@ -171,6 +709,20 @@ func (p *Parser) peekTokenIs(t token.TokenType) bool {
 	return p.peekToken.Type == t
 }
 
+func (p *Parser) peekPrecedence() int {
+	if precedence, ok := precedences[p.peekToken.Type]; ok {
+		return precedence
+	}
+	return LOWEST
+}
+
+func (p *Parser) curPrecedence() int {
+	if precedence, ok := precedences[p.curToken.Type]; ok {
+		return precedence
+	}
+	return LOWEST
+}
+
 func (p *Parser) expectPeek(t token.TokenType) bool {
 	if p.peekTokenIs(t) {
 		p.nextToken()
@@ -183,11 +735,82 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 
 // Error tracking methods
 
-func (p *Parser) Errors() []string {
+func (p *Parser) Errors() ErrorList {
 	return p.errors
 }
 
+// Halted reports whether the WithMaxErrors limit was reached; any input
+// after the last reported error was not parsed
+func (p *Parser) Halted() bool {
+	return p.halted
+}
+
+func (p *Parser) Warnings() ErrorList {
+	return p.warnings
+}
+
+func newError(tok token.Token, code ErrorCode, expected token.TokenType, msg string) *Error {
+	return &Error{
+		Pos:      tok.Pos,
+		End:      tok.End,
+		Code:     code,
+		Expected: expected,
+		Actual:   tok.Type,
+		Msg:      msg,
+	}
+}
+
+// addError records an error unless the parser is already recovering from
+// one, so a single mistake does not cascade into bogus follow-up errors
+func (p *Parser) addError(tok token.Token, code ErrorCode, expected token.TokenType, msg string) {
+	if p.panicking || p.halted {
+		return
+	}
+	p.panicking = true
+	p.errors = append(p.errors, newError(tok, code, expected, msg))
+
+	if p.maxErrors > 0 && len(p.errors) >= p.maxErrors {
+		p.halted = true
+	}
+}
+
+// synchronize skips tokens until the current one ends a statement or closes
+// the enclosing block, or the next one is a statement keyword or closes the
+// enclosing block. Braces opened by the broken statement, whose nesting
+// started at depth, are skipped as a whole; the enclosing block's brace is
+// left for parseBlockStatement.
+func (p *Parser) synchronize(depth int) {
+	p.panicking = false
+	for !p.curTokenIs(token.EOF) {
+		// The brace that closes the enclosing block drops below depth as
+		// soon as it becomes the current token
+		if p.curTokenIs(token.RBRACE) && p.braceDepth < depth {
+			return
+		}
+		if p.braceDepth <= depth {
+			if p.curTokenIs(token.SEMICOLON) {
+				return
+			}
+			switch p.peekToken.Type {
+			case token.LET, token.RETURN, token.RBRACE, token.EOF:
+				return
+			}
+		}
+		p.nextToken()
+	}
+}
+
 func (p *Parser) peekError(t token.TokenType) {
 	msg := fmt.Sprintf("expected next token to be %s, got %s instead", t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.addError(p.peekToken, ErrUnexpectedToken, t, msg)
+}
+
+func (p *Parser) unclosedError(code ErrorCode, opening token.Token, closing token.TokenType, construct string) {
+	msg := fmt.Sprintf("expected %s to close %s opened at %s, got %s instead", closing, construct, opening.Pos, p.peekToken.Type)
+	p.addError(p.peekToken, code, closing, msg)
+}
+
+func (p *Parser) noPrefixParseFnError(t token.TokenType) {
+	msg := fmt.Sprintf("no prefix parse function for %s found", t)
+	p.addError(p.curToken, ErrNoPrefixParseFn, "", msg)
 }
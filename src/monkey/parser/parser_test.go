@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"fmt"
 	"monkey/ast"
 	"monkey/lexer"
 	"testing"
@@ -9,7 +10,8 @@ import (
 func TestLetStatements(t *testing.T) {
 	input := `
 	let x = 5;
-	let y = 10; let foobar = 2323232;
+	let y = true; let foobar = y;
+	let z = 1 + 2 * 3
 	`
 
 	testProgram := makeProgram(t, input)
@@ -17,16 +19,18 @@ func TestLetStatements(t *testing.T) {
 	if testProgram == nil {
 		t.Fatal("ParseProgram() returned nil")
 	}
-	if len(testProgram.Statements) != 3 {
-		t.Fatalf("program.Statements does not contain 3 statements. got=%d", len(testProgram.Statements))
+	if len(testProgram.Statements) != 4 {
+		t.Fatalf("program.Statements does not contain 4 statements. got=%d", len(testProgram.Statements))
 	}
 
 	tests := []struct {
 		expectedIdentifier string
+		expectedValue      string
 	}{
-		{"x"},
-		{"y"},
-		{"foobar"},
+		{"x", "5"},
+		{"y", "true"},
+		{"foobar", "y"},
+		{"z", "(1 + (2 * 3))"},
 	}
 
 	for i, tt := range tests {
@@ -34,26 +38,83 @@ func TestLetStatements(t *testing.T) {
 		if !testLetStatement(t, stmt, tt.expectedIdentifier) {
 			return
 		}
+		value := stmt.(*ast.LetStatement).Value
+		if value == nil || value.String() != tt.expectedValue {
+			t.Errorf("letStmt.Value wrong. expected=%q, got=%v", tt.expectedValue, value)
+		}
 	}
 }
 
 func TestReturnStatements(t *testing.T) {
 	input := `
 	return 5;
-	return 10;
-	return add(15);
-	`
+	return 10 * x;
+	return;
+	return y`
 
 	testProgram := makeProgram(t, input)
 
-	if len(testProgram.Statements) != 3 {
-		t.Fatalf("program.Statements does not contain 3 statements. got=%d", len(testProgram.Statements))
+	if len(testProgram.Statements) != 4 {
+		t.Fatalf("program.Statements does not contain 4 statements. got=%d", len(testProgram.Statements))
+	}
+
+	tests := []struct {
+		expectedValue string
+	}{
+		{"5"},
+		{"(10 * x)"},
+		{""},
+		{"y"},
 	}
 
-	for _, stmt := range testProgram.Statements {
+	for i, stmt := range testProgram.Statements {
 		if !testReturnStatement(t, stmt) {
 			return
 		}
+		value := stmt.(*ast.ReturnStatement).ReturnValue
+		actual := ""
+		if value != nil {
+			actual = value.String()
+		}
+		if actual != tests[i].expectedValue {
+			t.Errorf("returnStmt.ReturnValue wrong. expected=%q, got=%q", tests[i].expectedValue, actual)
+		}
+	}
+}
+
+func TestAssignStatements(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedName     string
+		expectedOperator string
+		expectedValue    string
+	}{
+		{"x = 5;", "x", "=", "5"},
+		{"x += 1;", "x", "+=", "1"},
+		{"y -= a * b;", "y", "-=", "(a * b)"},
+		{"z *= 2", "z", "*=", "2"},
+		{"w /= -w;", "w", "/=", "(-w)"},
+	}
+
+	for _, tt := range tests {
+		testProgram := makeProgram(t, tt.input)
+
+		if len(testProgram.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(testProgram.Statements))
+		}
+		stmt, ok := testProgram.Statements[0].(*ast.AssignStatement)
+		if !ok {
+			t.Fatalf("stmt is not *ast.AssignStatement. got=%T", testProgram.Statements[0])
+		}
+		if stmt.Name.Value != tt.expectedName {
+			t.Errorf("stmt.Name.Value not '%s'. got=%s", tt.expectedName, stmt.Name.Value)
+		}
+		if stmt.Operator != tt.expectedOperator {
+			t.Errorf("stmt.Operator not '%s'. got=%s", tt.expectedOperator, stmt.Operator)
+		}
+		if stmt.Value.String() != tt.expectedValue {
+			t.Errorf("stmt.Value wrong. expected=%q, got=%q", tt.expectedValue, stmt.Value.String())
+		}
 	}
 }
 
@@ -85,7 +146,7 @@ func TestIdentifierExpressions(t *testing.T) {
 }
 
 func TestIntegerExpressions(t *testing.T) {
-	input := "5; 10;"
+	input := "5; 10; 0xff; 0o17; 0b101; 1_000; 010;"
 	testProgram := makeProgram(t, input)
 
 	tests := []struct {
@@ -94,6 +155,11 @@ func TestIntegerExpressions(t *testing.T) {
 	}{
 		{5, "5"},
 		{10, "10"},
+		{255, "0xff"},
+		{15, "0o17"},
+		{5, "0b101"},
+		{1000, "1_000"},
+		{10, "010"},
 	}
 	for i, stmt := range testProgram.Statements {
 		expStmt, ok := stmt.(*ast.ExpressionStatement)
@@ -108,6 +174,686 @@ func TestIntegerExpressions(t *testing.T) {
 
 }
 
+func TestBooleanExpressions(t *testing.T) {
+	input := "true; false; True; False;"
+	testProgram := makeProgram(t, input)
+
+	tests := []struct {
+		expectedValue   bool
+		expectedLiteral string
+	}{
+		{true, "true"},
+		{false, "false"},
+		{true, "True"},
+		{false, "False"},
+	}
+	if len(testProgram.Statements) != len(tests) {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", len(tests), len(testProgram.Statements))
+	}
+	for i, stmt := range testProgram.Statements {
+		expStmt, ok := stmt.(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("stmt is not *ast.ExpressionStatement. got=%T", stmt)
+		}
+		if !testBooleanExpression(t, &expStmt.Expression, tests[i].expectedValue, tests[i].expectedLiteral) {
+			return
+		}
+	}
+}
+
+func TestBooleanAliasModes(t *testing.T) {
+	input := "true; True;"
+
+	warnParser := New(lexer.New(input), WithBooleanAliases(WarnBooleanAliases))
+	warnProgram := warnParser.ParseProgram()
+	checkParserErrors(t, warnParser)
+	if len(warnProgram.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(warnProgram.Statements))
+	}
+	if len(warnParser.Warnings()) != 1 {
+		t.Fatalf("parser has wrong number of warnings. expected=1, got=%d (%q)", len(warnParser.Warnings()), warnParser.Warnings())
+	}
+
+	rejectParser := New(lexer.New(input), WithBooleanAliases(RejectBooleanAliases))
+	rejectParser.ParseProgram()
+	if len(rejectParser.Errors()) != 1 {
+		t.Fatalf("parser has wrong number of errors. expected=1, got=%d (%q)", len(rejectParser.Errors()), rejectParser.Errors())
+	}
+	expected := `boolean alias "True" is not allowed, use "true"`
+	if rejectParser.Errors()[0].Msg != expected {
+		t.Errorf("wrong error message. expected=%q, got=%q", expected, rejectParser.Errors()[0].Msg)
+	}
+}
+
+func TestBigIntegerExpressions(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedBig string
+	}{
+		{"9223372036854775807", ""},
+		{"9223372036854775808", "9223372036854775808"},
+		{"99999999999999999999", "99999999999999999999"},
+		{"0xffff_ffff_ffff_ffff_ff", "4722366482869645213695"},
+		{"000123456789012345678901", "123456789012345678901"},
+	}
+
+	for _, tt := range tests {
+		testProgram := makeProgram(t, tt.input)
+		stmt := testProgram.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("stmt.Expression is not *ast.IntegerLiteral. got=%T", stmt.Expression)
+		}
+		if tt.expectedBig == "" {
+			if literal.IsBig() {
+				t.Errorf("%s - literal promoted to big integer", tt.input)
+			}
+			continue
+		}
+		if !literal.IsBig() {
+			t.Fatalf("%s - literal not promoted to big integer", tt.input)
+		}
+		if literal.Big.String() != tt.expectedBig {
+			t.Errorf("literal.Big not %s. got=%s", tt.expectedBig, literal.Big)
+		}
+		if literal.String() != tt.input {
+			t.Errorf("literal.String() not %s. got=%s", tt.input, literal.String())
+		}
+	}
+}
+
+func TestFloatExpressions(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedValue float64
+	}{
+		{"3.14", 3.14},
+		{"1e3", 1000},
+		{"2.5E-1", 0.25},
+		{"1_000.5", 1000.5},
+	}
+
+	for _, tt := range tests {
+		testProgram := makeProgram(t, tt.input)
+		stmt := testProgram.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("stmt.Expression is not *ast.FloatLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expectedValue {
+			t.Errorf("literal.Value not %g. got=%g", tt.expectedValue, literal.Value)
+		}
+		if literal.TokenLiteral() != tt.input {
+			t.Errorf("literal.TokenLiteral not %s. got=%s", tt.input, literal.TokenLiteral())
+		}
+	}
+}
+
+func TestFloatRangeErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1e400", `1:1: float literal "1e400" is out of range`},
+		{"x + 2.5e-400", `1:5: float literal "2.5e-400" is out of range`},
+	}
+
+	for _, tt := range tests {
+		testParser := New(lexer.New(tt.input))
+		testParser.ParseProgram()
+		errors := testParser.Errors()
+		if len(errors) != 1 || errors[0].Error() != tt.expected || errors[0].Code != ErrInvalidFloat {
+			t.Errorf("%q - errors wrong. expected=%q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+
+	testProgram := makeProgram(t, "0.0e-400")
+	if testProgram.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FloatLiteral).Value != 0 {
+		t.Errorf("zero literal did not parse as zero")
+	}
+}
+
+func TestStringLiteralExpression(t *testing.T) {
+	input := `"hello\tworld";`
+	testProgram := makeProgram(t, input)
+
+	stmt := testProgram.Statements[0].(*ast.ExpressionStatement)
+	literal, ok := stmt.Expression.(*ast.StringLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not *ast.StringLiteral. got=%T", stmt.Expression)
+	}
+	if literal.Value != "hello\tworld" {
+		t.Errorf("literal.Value not %q. got=%q", "hello\tworld", literal.Value)
+	}
+}
+
+func TestStringLiteralStringReparses(t *testing.T) {
+	inputs := []string{
+		`"plain"`,
+		`"tab\tnewline\nquote\"backslash\\"`,
+		`"\u{7}\u{1F600}변수"`,
+	}
+
+	for _, input := range inputs {
+		first := makeProgram(t, input)
+		second := makeProgram(t, first.String())
+		firstValue := first.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.StringLiteral).Value
+		secondValue := second.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.StringLiteral).Value
+		if firstValue != secondValue {
+			t.Errorf("String() does not re-escape %q. got=%q", firstValue, first.String())
+		}
+	}
+}
+
+func TestPrefixExpressions(t *testing.T) {
+	tests := []struct {
+		input        string
+		operator     string
+		integerValue int64
+	}{
+		{"!5;", "!", 5},
+		{"-15;", "-", 15},
+	}
+
+	for _, tt := range tests {
+		testProgram := makeProgram(t, tt.input)
+
+		if len(testProgram.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(testProgram.Statements))
+		}
+		stmt, ok := testProgram.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("stmt is not *ast.ExpressionStatement. got=%T", testProgram.Statements[0])
+		}
+		prefix_expression, ok := stmt.Expression.(*ast.PrefixExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not *ast.PrefixExpression. got=%T", stmt.Expression)
+		}
+		if prefix_expression.Operator != tt.operator {
+			t.Fatalf("prefix_expression.Operator is not '%s'. got=%s", tt.operator, prefix_expression.Operator)
+		}
+		if !testIntegerExpression(t, &prefix_expression.Right, tt.integerValue, fmt.Sprint(tt.integerValue)) {
+			return
+		}
+	}
+}
+
+func TestNoPrefixParseFunctionError(t *testing.T) {
+	testParser := New(lexer.New("+ 5;"))
+	testParser.ParseProgram()
+
+	errors := testParser.Errors()
+	if len(errors) != 1 {
+		t.Fatalf("parser has wrong number of errors. expected=1, got=%d (%q)", len(errors), errors)
+	}
+	expected := "no prefix parse function for + found"
+	if errors[0].Msg != expected {
+		t.Errorf("wrong error message. expected=%q, got=%q", expected, errors[0].Msg)
+	}
+}
+
+func TestIfExpression(t *testing.T) {
+	input := `if (x < y) { x }`
+	testProgram := makeProgram(t, input)
+
+	if len(testProgram.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(testProgram.Statements))
+	}
+	stmt, ok := testProgram.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("stmt is not *ast.ExpressionStatement. got=%T", testProgram.Statements[0])
+	}
+	if_expression, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not *ast.IfExpression. got=%T", stmt.Expression)
+	}
+	if if_expression.Condition.String() != "(x < y)" {
+		t.Errorf("if_expression.Condition wrong. got=%q", if_expression.Condition.String())
+	}
+	if len(if_expression.Consequence.Statements) != 1 {
+		t.Fatalf("consequence is not 1 statement. got=%d", len(if_expression.Consequence.Statements))
+	}
+	consequence, ok := if_expression.Consequence.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("Statements[0] is not *ast.ExpressionStatement. got=%T", if_expression.Consequence.Statements[0])
+	}
+	if !testIdentifierExpression(t, &consequence.Expression, "x", "x") {
+		return
+	}
+	if if_expression.Alternative != nil {
+		t.Errorf("if_expression.Alternative was not nil. got=%+v", if_expression.Alternative)
+	}
+}
+
+func TestIfElseExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"if (x < y) { x } else { y }", "if ((x < y)) { x; } else { y; }"},
+		{"if (a) { let b = 1; b } else { }", "if (a) { let b = 1; b; } else { }"},
+		{"if (a) { 1 } else if (b) { 2 } else { 3 }", "if (a) { 1; } else if (b) { 2; } else { 3; }"},
+		{"if (a) { 1 } else { if (b) { 2 } }", "if (a) { 1; } else if (b) { 2; }"},
+	}
+
+	for _, tt := range tests {
+		testProgram := makeProgram(t, tt.input)
+		actual := testProgram.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestIfExpressionStringReparses(t *testing.T) {
+	inputs := []string{
+		"if (x) { x }",
+		"if (x < y) { x } else { y }",
+		"if ((a + b) * c > 2) { fn(x) { x }(1) }",
+		"if (true) { let y = 1; return y; } else { 0 }",
+		"if (a) { 1 } else if (b) { 2 } else if (c) { 3 } else { 4 }",
+	}
+
+	for _, input := range inputs {
+		first := makeProgram(t, input).String()
+		second := makeProgram(t, first).String()
+		if first != second {
+			t.Errorf("String() does not re-parse to the same tree. first=%q, second=%q", first, second)
+		}
+	}
+}
+
+func TestFunctionLiteralParsing(t *testing.T) {
+	input := `fn(x, y) { x + y; }`
+	testProgram := makeProgram(t, input)
+
+	if len(testProgram.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(testProgram.Statements))
+	}
+	stmt, ok := testProgram.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("stmt is not *ast.ExpressionStatement. got=%T", testProgram.Statements[0])
+	}
+	function, ok := stmt.Expression.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not *ast.FunctionLiteral. got=%T", stmt.Expression)
+	}
+	if len(function.Parameters) != 2 {
+		t.Fatalf("function literal parameters wrong. want 2, got=%d", len(function.Parameters))
+	}
+	if function.Parameters[0].Value != "x" || function.Parameters[1].Value != "y" {
+		t.Errorf("function literal parameters wrong. got=%s, %s", function.Parameters[0], function.Parameters[1])
+	}
+	if len(function.Body.Statements) != 1 {
+		t.Fatalf("function.Body.Statements has not 1 statement. got=%d", len(function.Body.Statements))
+	}
+	if function.Body.String() != "{ (x + y); }" {
+		t.Errorf("function.Body wrong. got=%q", function.Body.String())
+	}
+}
+
+func TestFunctionParameterParsing(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedParams []string
+	}{
+		{"fn() {};", []string{}},
+		{"fn(x) {};", []string{"x"}},
+		{"fn(x, y, z) {};", []string{"x", "y", "z"}},
+	}
+
+	for _, tt := range tests {
+		testProgram := makeProgram(t, tt.input)
+		stmt := testProgram.Statements[0].(*ast.ExpressionStatement)
+		function := stmt.Expression.(*ast.FunctionLiteral)
+
+		if len(function.Parameters) != len(tt.expectedParams) {
+			t.Fatalf("length parameters wrong. want %d, got=%d", len(tt.expectedParams), len(function.Parameters))
+		}
+		for i, ident := range tt.expectedParams {
+			if function.Parameters[i].Value != ident {
+				t.Errorf("parameter %d wrong. want %s, got=%s", i, ident, function.Parameters[i].Value)
+			}
+		}
+	}
+}
+
+func TestCallExpressionParsing(t *testing.T) {
+	input := "add(1, 2 * 3, 4 + 5);"
+	testProgram := makeProgram(t, input)
+
+	if len(testProgram.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(testProgram.Statements))
+	}
+	stmt, ok := testProgram.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("stmt is not *ast.ExpressionStatement. got=%T", testProgram.Statements[0])
+	}
+	call, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not *ast.CallExpression. got=%T", stmt.Expression)
+	}
+	if !testIdentifierExpression(t, &call.Function, "add", "add") {
+		return
+	}
+	if len(call.Arguments) != 3 {
+		t.Fatalf("wrong length of arguments. got=%d", len(call.Arguments))
+	}
+	if !testIntegerExpression(t, &call.Arguments[0], 1, "1") {
+		return
+	}
+	testInfixExpression(t, &call.Arguments[1], 2, "*", 3)
+	testInfixExpression(t, &call.Arguments[2], 4, "+", 5)
+}
+
+func TestUnclosedGroupedExpression(t *testing.T) {
+	testParser := New(lexer.New("(1 + 2;"))
+	testParser.ParseProgram()
+
+	errors := testParser.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("parser reported no errors for an unclosed group")
+	}
+	expected := "expected ) to close grouped expression opened at 1:1, got ; instead"
+	if errors[0].Msg != expected {
+		t.Errorf("wrong error message. expected=%q, got=%q", expected, errors[0].Msg)
+	}
+}
+
+func TestArrayLiteralParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2 * 2, 3 + 3]", "[1, (2 * 2), (3 + 3)]"},
+		{"[]", "[]"},
+		{"[1, 2, 3,]", "[1, 2, 3]"},
+		{"[[1], [\"a\", true],\n]", "[[1], [\"a\", true]]"},
+	}
+
+	for _, tt := range tests {
+		testProgram := makeProgram(t, tt.input)
+		stmt := testProgram.Statements[0].(*ast.ExpressionStatement)
+		if _, ok := stmt.Expression.(*ast.ArrayLiteral); !ok {
+			t.Fatalf("stmt.Expression is not *ast.ArrayLiteral. got=%T", stmt.Expression)
+		}
+		if testProgram.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, testProgram.String())
+		}
+	}
+}
+
+func TestIndexExpressionParsing(t *testing.T) {
+	input := "myArray[1 + 1]"
+	testProgram := makeProgram(t, input)
+
+	stmt := testProgram.Statements[0].(*ast.ExpressionStatement)
+	index, ok := stmt.Expression.(*ast.IndexExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not *ast.IndexExpression. got=%T", stmt.Expression)
+	}
+	if !testIdentifierExpression(t, &index.Left, "myArray", "myArray") {
+		return
+	}
+	if !testInfixExpression(t, &index.Index, 1, "+", 1) {
+		return
+	}
+	if index.Pos().String() != "1:1" || index.End().String() != "1:15" {
+		t.Errorf("index range wrong. got=%s-%s", index.Pos(), index.End())
+	}
+}
+
+func TestUnclosedBrackets(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2;", "1:6: expected ] to close array literal opened at 1:1, got ; instead"},
+		{"let a = [1\nlet b = 2;", "2:1: expected ] to close array literal opened at 1:9, got LET instead"},
+		{"xs[1;", "1:5: expected ] to close index expression opened at 1:3, got ; instead"},
+		{"f(1 2)", "1:5: expected ) to close call arguments opened at 1:2, got INT instead"},
+	}
+
+	for _, tt := range tests {
+		testParser := New(lexer.New(tt.input))
+		testParser.ParseProgram()
+		errors := testParser.Errors()
+		if len(errors) != 1 || errors[0].Error() != tt.expected {
+			t.Errorf("%q - errors wrong. expected=%q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
+func TestHashLiteralParsing(t *testing.T) {
+	input := `{"name": "x", 1: true, two: 1 + 1, "z": [1],}`
+	testProgram := makeProgram(t, input)
+
+	stmt := testProgram.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not *ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	expected := []struct {
+		key   string
+		value string
+	}{
+		{`"name"`, `"x"`},
+		{"1", "true"},
+		{"two", "(1 + 1)"},
+		{`"z"`, "[1]"},
+	}
+	if len(hash.Pairs) != len(expected) {
+		t.Fatalf("hash.Pairs has wrong length. expected=%d, got=%d", len(expected), len(hash.Pairs))
+	}
+	for i, tt := range expected {
+		if hash.Pairs[i].Key.String() != tt.key || hash.Pairs[i].Value.String() != tt.value {
+			t.Errorf("hash.Pairs[%d] wrong. expected=%s: %s, got=%s: %s", i, tt.key, tt.value, hash.Pairs[i].Key, hash.Pairs[i].Value)
+		}
+	}
+	if hash.String() != `{"name": "x", 1: true, two: (1 + 1), "z": [1]}` {
+		t.Errorf("hash.String() wrong. got=%q", hash.String())
+	}
+}
+
+func TestHashLiteralInExpressionPosition(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"{}", "{}"},
+		{"let h = {};", "let h = {};"},
+		{"if (x) { {1: 2} }", "if (x) { {1: 2}; }"},
+		{"fn() { {} }", "fn() { {}; }"},
+		{`{"a": {"b": 1}}["a"]`, `({"a": {"b": 1}}["a"])`},
+	}
+
+	for _, tt := range tests {
+		testProgram := makeProgram(t, tt.input)
+		if testProgram.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, testProgram.String())
+		}
+	}
+}
+
+func TestMalformedHashLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"a" 1}`, "1:6: expected next token to be :, got INT instead"},
+		{`{"a": 1 "b": 2}`, `1:9: expected } to close hash literal opened at 1:1, got STRING instead`},
+	}
+
+	for _, tt := range tests {
+		testParser := New(lexer.New(tt.input))
+		testParser.ParseProgram()
+		errors := testParser.Errors()
+		if len(errors) != 1 || errors[0].Error() != tt.expected {
+			t.Errorf("%q - errors wrong. expected=%q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
+func TestLogicalExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		operator string
+	}{
+		{"a && b", "&&"},
+		{"a || b", "||"},
+	}
+
+	for _, tt := range tests {
+		testProgram := makeProgram(t, tt.input)
+		stmt := testProgram.Statements[0].(*ast.ExpressionStatement)
+		logical, ok := stmt.Expression.(*ast.LogicalExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not *ast.LogicalExpression. got=%T", stmt.Expression)
+		}
+		if logical.Operator != tt.operator {
+			t.Errorf("logical.Operator is not '%s'. got=%s", tt.operator, logical.Operator)
+		}
+		if !testIdentifierExpression(t, &logical.Left, "a", "a") || !testIdentifierExpression(t, &logical.Right, "b", "b") {
+			return
+		}
+	}
+}
+
+func TestInfixExpressions(t *testing.T) {
+	tests := []struct {
+		input      string
+		leftValue  int64
+		operator   string
+		rightValue int64
+	}{
+		{"5 + 5;", 5, "+", 5},
+		{"5 - 5;", 5, "-", 5},
+		{"5 * 5;", 5, "*", 5},
+		{"5 / 5;", 5, "/", 5},
+		{"5 > 5;", 5, ">", 5},
+		{"5 < 5;", 5, "<", 5},
+		{"5 >= 5;", 5, ">=", 5},
+		{"5 <= 5;", 5, "<=", 5},
+		{"5 == 5;", 5, "==", 5},
+		{"5 != 5;", 5, "!=", 5},
+	}
+
+	for _, tt := range tests {
+		testProgram := makeProgram(t, tt.input)
+
+		if len(testProgram.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(testProgram.Statements))
+		}
+		stmt, ok := testProgram.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("stmt is not *ast.ExpressionStatement. got=%T", testProgram.Statements[0])
+		}
+		if !testInfixExpression(t, &stmt.Expression, tt.leftValue, tt.operator, tt.rightValue) {
+			return
+		}
+	}
+}
+
+func TestOperatorPrecedenceParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"-a * b", "((-a) * b)"},
+		{"!-a", "(!(-a))"},
+		{"a + b + c", "((a + b) + c)"},
+		{"3 > 5 == false", "((3 > 5) == false)"},
+		{"!True", "(!True)"},
+		{"a + b - c", "((a + b) - c)"},
+		{"a * b * c", "((a * b) * c)"},
+		{"a * b / c", "((a * b) / c)"},
+		{"a + b / c", "(a + (b / c))"},
+		{"1 + 2 * 3", "(1 + (2 * 3))"},
+		{"a + b * c + d / e - f", "(((a + (b * c)) + (d / e)) - f)"},
+		{"3 + 4; 5 * 5", "(3 + 4)(5 * 5)"},
+		{"5 > 4 == 3 < 4", "((5 > 4) == (3 < 4))"},
+		{"5 < 4 != 3 > 4", "((5 < 4) != (3 > 4))"},
+		{"1 < 2 >= 3 > 4", "((1 < 2) >= (3 > 4))"},
+		{"a <= b == c >= d", "((a <= b) == (c >= d))"},
+		{"3 + 4 * 5 == 3 * 1 + 4 * 5", "((3 + (4 * 5)) == ((3 * 1) + (4 * 5)))"},
+		{"1 + (2 + 3) + 4", "((1 + (2 + 3)) + 4)"},
+		{"1 + 2.5 * x", "(1 + (2.5 * x))"},
+		{"(5 + 5) * 2", "((5 + 5) * 2)"},
+		{"2 / (5 + 5)", "(2 / (5 + 5))"},
+		{"-(5 + 5)", "(-(5 + 5))"},
+		{"!(true == true)", "(!(true == true))"},
+		{"a + add(b * c) + d", "((a + add((b * c))) + d)"},
+		{"add(a, b, 1, 2 * 3, 4 + 5, add(6, 7 * 8))", "add(a, b, 1, (2 * 3), (4 + 5), add(6, (7 * 8)))"},
+		{"add(a + b + c * d / f + g)", "add((((a + b) + ((c * d) / f)) + g))"},
+		{"-f(x)", "(-f(x))"},
+		{"fn(x) { x }(5)", "fn(x) { x; }(5)"},
+		{"a * [1, 2, 3, 4][b * c] * d", "((a * ([1, 2, 3, 4][(b * c)])) * d)"},
+		{"add(a * b[2], b[1], 2 * [1, 2][1])", "add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))"},
+		{"f(x)[0]", "(f(x)[0])"},
+		{"a || b && c", "(a || (b && c))"},
+		{"a && b || c && d", "((a && b) || (c && d))"},
+		{"a == b && c < d || !e", "(((a == b) && (c < d)) || (!e))"},
+		{"a || b || c", "((a || b) || c)"},
+		{"-xs[0]", "(-(xs[0]))"},
+	}
+
+	for _, tt := range tests {
+		testProgram := makeProgram(t, tt.input)
+		actual := testProgram.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestNodePositions(t *testing.T) {
+	input := "let add = fn(a, b) {\n  a + b\n};\nadd(1, -2);\n(a)\nlet c = -(a + b) * (c);"
+	testProgram := makeProgram(t, input)
+
+	if len(testProgram.Statements) != 4 {
+		t.Fatalf("program.Statements does not contain 4 statements. got=%d", len(testProgram.Statements))
+	}
+	letStmt := testProgram.Statements[0].(*ast.LetStatement)
+	function := letStmt.Value.(*ast.FunctionLiteral)
+	body := function.Body.Statements[0].(*ast.ExpressionStatement)
+	call := testProgram.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	group := testProgram.Statements[2].(*ast.ExpressionStatement)
+	groupLet := testProgram.Statements[3].(*ast.LetStatement)
+	product := groupLet.Value.(*ast.InfixExpression)
+	negation := product.Left.(*ast.PrefixExpression)
+
+	tests := []struct {
+		node          ast.Node
+		expectedStart string
+		expectedEnd   string
+	}{
+		{testProgram, "1:1", "6:23"},
+		{letStmt, "1:1", "3:2"},
+		{function, "1:11", "3:2"},
+		{function.Body, "1:20", "3:2"},
+		{body.Expression, "2:3", "2:8"},
+		{call, "4:1", "4:11"},
+		{call.Arguments[1], "4:8", "4:10"},
+		{group, "5:1", "5:4"},
+		{group.Expression, "5:1", "5:4"},
+		{groupLet, "6:1", "6:23"},
+		{product, "6:9", "6:23"},
+		{negation, "6:9", "6:17"},
+		{negation.Right, "6:10", "6:17"},
+		{product.Right, "6:20", "6:23"},
+	}
+
+	for i, tt := range tests {
+		if tt.node.Pos().String() != tt.expectedStart {
+			t.Errorf("tests[%d] - %T start wrong. expected=%s, got=%s", i, tt.node, tt.expectedStart, tt.node.Pos())
+		}
+		if tt.node.End().String() != tt.expectedEnd {
+			t.Errorf("tests[%d] - %T end wrong. expected=%s, got=%s", i, tt.node, tt.expectedEnd, tt.node.End())
+		}
+	}
+}
+
 // Statement Checking Internal Functions
 
 func testLetStatement(t *testing.T, s ast.Statement, name string) bool {
@@ -185,6 +931,44 @@ func testIntegerExpression(t *testing.T, expr_ptr *ast.Expression, expectedValue
 	return true
 }
 
+func testBooleanExpression(t *testing.T, expr_ptr *ast.Expression, expectedValue bool, expectedLiteral string) bool {
+	expression := *expr_ptr
+	boolean_expression, ok := expression.(*ast.Boolean)
+	if !ok {
+		t.Errorf("expression is not *ast.Boolean. got=%T", expression)
+		return false
+	}
+	if boolean_expression.Value != expectedValue {
+		t.Errorf("boolean_expression.Value not %t. got=%t", expectedValue, boolean_expression.Value)
+		return false
+	}
+	if boolean_expression.TokenLiteral() != expectedLiteral {
+		t.Errorf("boolean_expression.TokenLiteral not %s. got=%s", expectedLiteral, boolean_expression.TokenLiteral())
+		return false
+	}
+	return true
+}
+
+func testInfixExpression(t *testing.T, expr_ptr *ast.Expression, left int64, operator string, right int64) bool {
+	expression := *expr_ptr
+	infix_expression, ok := expression.(*ast.InfixExpression)
+	if !ok {
+		t.Errorf("expression is not *ast.InfixExpression. got=%T(%s)", expression, expression)
+		return false
+	}
+	if !testIntegerExpression(t, &infix_expression.Left, left, fmt.Sprint(left)) {
+		return false
+	}
+	if infix_expression.Operator != operator {
+		t.Errorf("infix_expression.Operator is not '%s'. got=%q", operator, infix_expression.Operator)
+		return false
+	}
+	if !testIntegerExpression(t, &infix_expression.Right, right, fmt.Sprint(right)) {
+		return false
+	}
+	return true
+}
+
 // Error Checking Internal Functions
 
 func checkParserErrors(t *testing.T, p *Parser) {
@@ -204,6 +988,7 @@ func makeProgram(t *testing.T, input string) *ast.Program {
 
 	testLexer := lexer.New(input)
 	testParser := New(testLexer)
+	program := testParser.ParseProgram()
 	checkParserErrors(t, testParser)
-	return testParser.ParseProgram()
+	return program
 }
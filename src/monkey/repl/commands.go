@@ -0,0 +1,181 @@
+package repl
+
+import (
+	"errors"
+	"fmt"
+	"monkey/object"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CommandFunc runs a command with the rest of its line, trimmed
+type CommandFunc func(s *Session, args string) error
+
+// Command is a meta-command, typed at the prompt after a colon
+type Command struct {
+	Name string // Without the leading colon
+	Args string // Argument synopsis shown by :help, e.g. "<file>"
+	Help string
+	Run  CommandFunc
+}
+
+var (
+	commandsMu sync.RWMutex
+	commands   = map[string]*Command{}
+)
+
+// RegisterCommand makes a command available in every session started after
+// the call. It fails if a command with the same name is already registered.
+func RegisterCommand(command *Command) error {
+	commandsMu.Lock()
+	defer commandsMu.Unlock()
+
+	if _, ok := commands[command.Name]; ok {
+		return fmt.Errorf("command :%s is already registered", command.Name)
+	}
+	commands[command.Name] = command
+	return nil
+}
+
+// registeredCommands copies the registered commands, so a session keeps the
+// table it started with however the registry changes afterwards
+func registeredCommands() map[string]*Command {
+	commandsMu.RLock()
+	defer commandsMu.RUnlock()
+
+	table := make(map[string]*Command, len(commands))
+	for name, command := range commands {
+		table[name] = command
+	}
+	return table
+}
+
+func init() {
+	for _, command := range []*Command{
+		{Name: "eval", Help: "evaluate input and print the result", Run: modeCommand(EvalMode)},
+		{Name: "tokens", Help: "print the tokens of each input", Run: modeCommand(TokensMode)},
+		{Name: "ast", Help: "print the syntax tree of each input", Run: modeCommand(AstMode)},
+		{Name: "load", Args: "<file>", Help: "evaluate a file in the current environment", Run: loadCommand},
+		{Name: "save", Args: "<file>", Help: "write the statements run so far to a file", Run: saveCommand},
+		{Name: "reset", Help: "clear all bindings and saved inputs", Run: resetCommand},
+		{Name: "env", Help: "list the bindings in the current environment", Run: envCommand},
+		{Name: "time", Args: "<input>", Help: "evaluate input and print how long it took", Run: timeCommand},
+		{Name: "help", Help: "list the available commands", Run: helpCommand},
+	} {
+		if err := RegisterCommand(command); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// runCommand dispatches a line that starts with a colon
+func (s *Session) runCommand(line string) {
+	name, args, _ := strings.Cut(strings.TrimSpace(line)[1:], " ")
+
+	command, ok := s.commands[name]
+	if !ok {
+		fmt.Fprintf(s.out, "unknown command :%s, type :help for a list\n", name)
+		return
+	}
+	if err := command.Run(s, strings.TrimSpace(args)); err != nil {
+		fmt.Fprintf(s.out, ":%s: %s\n", name, err)
+	}
+}
+
+// Builtin commands
+
+func modeCommand(mode Mode) CommandFunc {
+	return func(s *Session, args string) error {
+		s.mode = mode
+		return nil
+	}
+}
+
+// loadCommand evaluates the file's statements one at a time, as if each had
+// been typed at the prompt, so a failing statement does not stop the rest
+func loadCommand(s *Session, args string) error {
+	if args == "" {
+		return errors.New("usage: :load <file>")
+	}
+	content, err := os.ReadFile(args)
+	if err != nil {
+		return err
+	}
+
+	source := string(content)
+	program, ok := s.parse(source)
+	if !ok {
+		return nil
+	}
+
+	// Only errors are printed; the values of the file's statements are not
+	for _, statement := range program.Statements {
+		if errObj, ok := s.exec(source, statement).(*object.Error); ok {
+			fmt.Fprintln(s.out, errObj.Inspect())
+		}
+	}
+	return nil
+}
+
+// saveCommand writes the statements run since the last :reset, one per
+// line. Loading the file into a fresh session replays this one exactly.
+func saveCommand(s *Session, args string) error {
+	if args == "" {
+		return errors.New("usage: :save <file>")
+	}
+	content := ""
+	if len(s.accepted) > 0 {
+		content = strings.Join(s.accepted, "\n") + "\n"
+	}
+	return os.WriteFile(args, []byte(content), 0644)
+}
+
+func resetCommand(s *Session, args string) error {
+	s.Reset()
+	return nil
+}
+
+func envCommand(s *Session, args string) error {
+	for _, name := range s.env.Names() {
+		value, _ := s.env.Get(name)
+		fmt.Fprintf(s.out, "%s = %s\n", name, value.Inspect())
+	}
+	return nil
+}
+
+func timeCommand(s *Session, args string) error {
+	if args == "" {
+		return errors.New("usage: :time <input>")
+	}
+
+	start := time.Now()
+	evaluated, ok := s.Eval(args)
+	elapsed := time.Since(start)
+	if !ok {
+		return nil
+	}
+
+	if evaluated != nil {
+		fmt.Fprintln(s.out, evaluated.Inspect())
+	}
+	fmt.Fprintf(s.out, "time: %s\n", elapsed)
+	return nil
+}
+
+func helpCommand(s *Session, args string) error {
+	names := []string{}
+	for name := range s.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		command := s.commands[name]
+		usage := strings.TrimSpace(":" + command.Name + " " + command.Args)
+		fmt.Fprintf(s.out, "  %-16s %s\n", usage, command.Help)
+	}
+	return nil
+}
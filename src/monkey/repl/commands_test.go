@@ -0,0 +1,235 @@
+package repl
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestEnvAndResetCommands(t *testing.T) {
+	input := "let b = [1, 2];\nlet a = fn(x) { x };\n:env\n:reset\n:env\na\n"
+	expected := []string{
+		"a = fn(x) { x; }",
+		"b = [1, 2]",
+		"ERROR: 1:1: identifier not found: a",
+	}
+
+	testOutput(t, input, expected)
+}
+
+func TestLoadCommand(t *testing.T) {
+	dir := t.TempDir()
+	script := writeFile(t, dir, "script.monkey", "let square = fn(x) {\n  x * x\n};\nlet nine = square(3);\n")
+	broken := writeFile(t, dir, "broken.monkey", "let x = 1;\nlet = 2;\n")
+	failing := writeFile(t, dir, "failing.monkey", "let y = 1;\ny + true\n")
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{":load " + script + "\nsquare(nine)\n", []string{"81"}},
+		{":load " + broken + "\nx\n", []string{
+			"2:5: error[E001]: expected next token to be IDENT, got = instead",
+			"let = 2;",
+			"    ^",
+			"ERROR: 1:1: identifier not found: x",
+		}},
+		{":load " + failing + "\ny\n", []string{"ERROR: 2:1: type mismatch: INTEGER + BOOLEAN", "1"}},
+		{":load " + filepath.Join(dir, "missing.monkey") + "\n", []string{
+			fmt.Sprintf(":load: open %s: no such file or directory", filepath.Join(dir, "missing.monkey")),
+		}},
+		{":load\n", []string{":load: usage: :load <file>"}},
+	}
+
+	for _, tt := range tests {
+		testOutput(t, tt.input, tt.expected)
+	}
+}
+
+func TestSaveCommand(t *testing.T) {
+	dir := t.TempDir()
+	saved := filepath.Join(dir, "session.monkey")
+
+	input := "let add = fn(a, b) {\n  a + b\n};\nlet = 1\n1 / 0\nlet sum = add(1, 2);\n:save " + saved + "\n"
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+
+	content, err := os.ReadFile(saved)
+	if err != nil {
+		t.Fatalf("saved file not readable: %s", err)
+	}
+	expected := "let add = fn(a, b) {\n  a + b\n};\n1 / 0;\nlet sum = add(1, 2);\n"
+	if string(content) != expected {
+		t.Errorf("wrong saved statements. expected=%q, got=%q", expected, content)
+	}
+
+	testOutput(t, ":load "+saved+"\nsum\n", []string{"ERROR: 4:1: division by zero", "3"})
+	testOutput(t, ":save\n", []string{":save: usage: :save <file>"})
+}
+
+func TestSaveKeepsWholeStatements(t *testing.T) {
+	saved := filepath.Join(t.TempDir(), "session.monkey")
+
+	input := "let y = (1 + 2);\nlet z = 2 * (y + 1)\n(z)\nlet w = -(z - y) + [(1)][0]; (w)\n:save " + saved + "\n"
+	testOutput(t, input, []string{"8", "-4"})
+
+	content, err := os.ReadFile(saved)
+	if err != nil {
+		t.Fatalf("saved file not readable: %s", err)
+	}
+	expected := "let y = (1 + 2);\nlet z = 2 * (y + 1);\n(z);\nlet w = -(z - y) + [(1)][0];\n(w);\n"
+	if string(content) != expected {
+		t.Errorf("wrong saved statements. expected=%q, got=%q", expected, content)
+	}
+
+	testOutput(t, ":load "+saved+"\n[y, z, w]\n", []string{"[3, 8, -4]"})
+}
+
+func TestSaveReplaysSession(t *testing.T) {
+	saved := filepath.Join(t.TempDir(), "session.monkey")
+
+	input := "let a = 1; a + true; let b = 2;\nif (true) { let d = a + 1; d + true }\n" +
+		":save " + saved + "\n:env\n:reset\n:load " + saved + "\n:env\n"
+	expected := []string{
+		"ERROR: 1:12: type mismatch: INTEGER + BOOLEAN",
+		"ERROR: 1:28: type mismatch: INTEGER + BOOLEAN",
+		"a = 1",
+		"d = 2",
+		"ERROR: 2:1: type mismatch: INTEGER + BOOLEAN",
+		"ERROR: 3:28: type mismatch: INTEGER + BOOLEAN",
+		"a = 1",
+		"d = 2",
+	}
+
+	testOutput(t, input, expected)
+}
+
+func TestTimeCommand(t *testing.T) {
+	var out bytes.Buffer
+	Start(strings.NewReader("let x = 20;\n:time x * 2\n:time let y = x;\ny\n"), &out)
+
+	lines := strings.Split(strings.TrimSuffix(strings.ReplaceAll(out.String(), PROMPT, ""), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("wrong number of output lines. got=%q", lines)
+	}
+	if lines[0] != "40" {
+		t.Errorf("wrong result. expected=%q, got=%q", "40", lines[0])
+	}
+	for _, line := range []string{lines[1], lines[2]} {
+		if !strings.HasPrefix(line, "time: ") {
+			t.Errorf("timing line missing. got=%q", line)
+		}
+	}
+	if lines[3] != "20" {
+		t.Errorf("binding made under :time not kept. got=%q", lines[3])
+	}
+}
+
+func TestHelpAndUnknownCommands(t *testing.T) {
+	var out bytes.Buffer
+	Start(strings.NewReader(":help\n:nope\n"), &out)
+	output := out.String()
+
+	for _, expected := range []string{
+		"  :ast             print the syntax tree of each input\n",
+		"  :load <file>     evaluate a file in the current environment\n",
+		"  :time <input>    evaluate input and print how long it took\n",
+		"unknown command :nope, type :help for a list\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("output does not contain %q. got=%q", expected, output)
+		}
+	}
+}
+
+func TestRegisterCommand(t *testing.T) {
+	err := RegisterCommand(&Command{
+		Name: "double",
+		Args: "<name>",
+		Help: "double an integer binding",
+		Run: func(s *Session, args string) error {
+			value, ok := s.Env().Get(args)
+			if !ok {
+				return fmt.Errorf("%s is not bound", args)
+			}
+			evaluated, _ := s.Eval(fmt.Sprintf("let %s = %s * 2;", args, value.Inspect()))
+			if evaluated != nil {
+				fmt.Fprintln(s.Out(), evaluated.Inspect())
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("RegisterCommand failed: %s", err)
+	}
+	defer unregisterCommand("double")
+
+	testOutput(t, "let n = 21;\n:double n\nn\n:double m\n", []string{"42", ":double: m is not bound"})
+
+	var out bytes.Buffer
+	Start(strings.NewReader(":help\n"), &out)
+	if !strings.Contains(out.String(), "  :double <name>   double an integer binding\n") {
+		t.Errorf("registered command missing from :help. got=%q", out.String())
+	}
+}
+
+func TestRegisterDuplicateCommand(t *testing.T) {
+	err := RegisterCommand(&Command{Name: "env", Help: "shadow :env", Run: resetCommand})
+	if err == nil || err.Error() != "command :env is already registered" {
+		t.Errorf("duplicate command not rejected. got=%v", err)
+	}
+
+	testOutput(t, "let x = 1;\n:env\n", []string{"x = 1"})
+}
+
+func TestSessionKeepsItsCommands(t *testing.T) {
+	var out bytes.Buffer
+	s := newSession(&out)
+
+	RegisterCommand(&Command{Name: "late", Help: "registered after the session started", Run: resetCommand})
+	defer unregisterCommand("late")
+
+	s.runCommand(":late")
+	if out.String() != "unknown command :late, type :help for a list\n" {
+		t.Errorf("session picked up a later command. got=%q", out.String())
+	}
+}
+
+func TestRegisterCommandConcurrently(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		name := fmt.Sprintf("concurrent%d", i)
+		defer unregisterCommand(name)
+
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterCommand(&Command{Name: name, Run: resetCommand})
+		}()
+		go func() {
+			defer wg.Done()
+			newSession(&bytes.Buffer{}).runCommand(":help")
+		}()
+	}
+	wg.Wait()
+}
+
+// Command Testing Internal Functions
+
+func unregisterCommand(name string) {
+	commandsMu.Lock()
+	defer commandsMu.Unlock()
+	delete(commands, name)
+}
+
+func writeFile(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("could not write %s: %s", path, err)
+	}
+	return path
+}
@@ -0,0 +1,353 @@
+package repl
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Keys the editor handles, as read from a terminal in raw mode
+
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlH     = 8
+	keyTab       = 9
+	keyNewline   = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyBackspace = 127
+)
+
+// defaultWidth is used when the terminal does not report its width
+const defaultWidth = 80
+
+// editor is the line reader used on interactive terminals. It supports
+// cursor movement, history recall with the arrow keys and tab completion.
+type editor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	term     *terminal // Put in raw mode while a line is read; nil leaves the mode alone
+	history  *history
+	complete func(prefix string) []string
+
+	prompt       string
+	line         []rune
+	cursor       int
+	offset       int    // The first rune shown when the line is wider than the terminal
+	historyIndex int    // The history entry shown; len(entries) is the line being typed
+	draft        []rune // The line being typed while history is browsed
+}
+
+func (e *editor) readLine(prompt string) (string, error) {
+	if e.term != nil {
+		if err := e.term.makeRaw(); err != nil {
+			return "", err
+		}
+		defer e.term.restore()
+	}
+
+	e.prompt = prompt
+	e.line = []rune{}
+	e.cursor = 0
+	e.offset = 0
+	e.historyIndex = len(e.history.entries)
+	e.refresh()
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case keyEnter, keyNewline:
+			fmt.Fprint(e.out, "\r\n")
+			return string(e.line), nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", errInterrupted
+		case keyCtrlD:
+			if len(e.line) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.deleteForward()
+		case keyTab:
+			e.completeWord()
+		case keyBackspace, keyCtrlH:
+			e.deleteBackward()
+		case keyCtrlA:
+			e.cursor = 0
+		case keyCtrlE:
+			e.cursor = len(e.line)
+		case keyCtrlB:
+			e.moveLeft()
+		case keyCtrlF:
+			e.moveRight()
+		case keyCtrlK:
+			e.line = e.line[:e.cursor]
+		case keyCtrlU:
+			e.line = e.line[e.cursor:]
+			e.cursor = 0
+		case keyCtrlW:
+			e.deleteWord()
+		case keyCtrlL:
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case keyCtrlP:
+			e.historyPrevious()
+		case keyCtrlN:
+			e.historyNext()
+		case keyEscape:
+			e.readEscape()
+		default:
+			if unicode.IsPrint(r) {
+				e.insert(r)
+			}
+		}
+		e.refresh()
+	}
+}
+
+// readEscape handles the escape sequences sent by the arrow, home, end and
+// delete keys; other sequences are ignored
+func (e *editor) readEscape() {
+	kind, _, err := e.in.ReadRune()
+	if err != nil || (kind != '[' && kind != 'O') {
+		return
+	}
+	key, _, err := e.in.ReadRune()
+	if err != nil {
+		return
+	}
+
+	// Sequences such as ESC [ 3 ~ carry a number before the final ~; ones
+	// that end otherwise, like modified arrows, are read and dropped
+	if unicode.IsDigit(key) {
+		for {
+			next, _, err := e.in.ReadRune()
+			if err != nil {
+				return
+			}
+			if next == '~' {
+				break
+			}
+			if next >= '@' && next <= '~' {
+				return
+			}
+		}
+	}
+
+	switch key {
+	case 'A':
+		e.historyPrevious()
+	case 'B':
+		e.historyNext()
+	case 'C':
+		e.moveRight()
+	case 'D':
+		e.moveLeft()
+	case 'H', '1', '7':
+		e.cursor = 0
+	case 'F', '4', '8':
+		e.cursor = len(e.line)
+	case '3':
+		e.deleteForward()
+	}
+}
+
+// Editing methods
+
+func (e *editor) insert(r rune) {
+	e.line = append(e.line, 0)
+	copy(e.line[e.cursor+1:], e.line[e.cursor:])
+	e.line[e.cursor] = r
+	e.cursor++
+}
+
+func (e *editor) insertString(s string) {
+	for _, r := range s {
+		e.insert(r)
+	}
+}
+
+func (e *editor) deleteBackward() {
+	if e.cursor > 0 {
+		e.line = append(e.line[:e.cursor-1], e.line[e.cursor:]...)
+		e.cursor--
+	}
+}
+
+func (e *editor) deleteForward() {
+	if e.cursor < len(e.line) {
+		e.line = append(e.line[:e.cursor], e.line[e.cursor+1:]...)
+	}
+}
+
+// deleteWord deletes back to the start of the word before the cursor
+func (e *editor) deleteWord() {
+	start := e.cursor
+	for start > 0 && e.line[start-1] == ' ' {
+		start--
+	}
+	for start > 0 && e.line[start-1] != ' ' {
+		start--
+	}
+	e.line = append(e.line[:start], e.line[e.cursor:]...)
+	e.cursor = start
+}
+
+func (e *editor) moveLeft() {
+	if e.cursor > 0 {
+		e.cursor--
+	}
+}
+
+func (e *editor) moveRight() {
+	if e.cursor < len(e.line) {
+		e.cursor++
+	}
+}
+
+// History methods
+
+func (e *editor) historyPrevious() {
+	if e.historyIndex == 0 {
+		return
+	}
+	if e.historyIndex == len(e.history.entries) {
+		e.draft = e.line
+	}
+	e.historyIndex--
+	e.setLine([]rune(e.history.entries[e.historyIndex]))
+}
+
+func (e *editor) historyNext() {
+	if e.historyIndex >= len(e.history.entries) {
+		return
+	}
+	e.historyIndex++
+	if e.historyIndex == len(e.history.entries) {
+		e.setLine(e.draft)
+		return
+	}
+	e.setLine([]rune(e.history.entries[e.historyIndex]))
+}
+
+// addHistory records a complete input, which may span several lines. The
+// loop calls it rather than readLine, so a block is recalled as a whole.
+func (e *editor) addHistory(entry string) {
+	e.history.add(entry)
+}
+
+func (e *editor) setLine(line []rune) {
+	e.line = append([]rune{}, line...)
+	e.cursor = len(e.line)
+}
+
+// Completion methods
+
+// completeWord completes the identifier before the cursor. A single match
+// is inserted whole; several matches are extended to their common prefix,
+// or listed when that adds nothing.
+func (e *editor) completeWord() {
+	start := e.cursor
+	for start > 0 && isWordRune(e.line[start-1]) {
+		start--
+	}
+	prefix := string(e.line[start:e.cursor])
+	if prefix == "" || e.complete == nil {
+		return
+	}
+
+	candidates := e.complete(prefix)
+	switch len(candidates) {
+	case 0:
+		fmt.Fprint(e.out, "\a")
+	case 1:
+		e.insertString(strings.TrimPrefix(candidates[0], prefix))
+	default:
+		common := commonPrefix(candidates)
+		if len(common) > len(prefix) {
+			e.insertString(strings.TrimPrefix(common, prefix))
+			return
+		}
+		fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func commonPrefix(words []string) string {
+	prefix := []rune(words[0])
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, string(prefix)) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return string(prefix)
+}
+
+// Utilities
+
+// refresh redraws the prompt and line, then moves back to the cursor. A
+// line too wide for the terminal is scrolled to keep the cursor in view,
+// and the newlines of a recalled multi-line entry are shown as ↵.
+func (e *editor) refresh() {
+	// The last column stays free so the terminal never wraps the line
+	room := e.columns() - displayWidth([]rune(e.prompt)) - 1
+	if room < 1 {
+		room = 1
+	}
+
+	if e.cursor < e.offset {
+		e.offset = e.cursor
+	}
+	for displayWidth(e.line[e.offset:e.cursor]) > room {
+		e.offset++
+	}
+	for e.offset > 0 && displayWidth(e.line[e.offset-1:]) <= room {
+		e.offset--
+	}
+	end := e.cursor
+	for end < len(e.line) && displayWidth(e.line[e.offset:end+1]) <= room {
+		end++
+	}
+
+	var out bytes.Buffer
+
+	out.WriteString("\r")
+	out.WriteString(e.prompt)
+	out.WriteString(strings.ReplaceAll(string(e.line[e.offset:end]), "\n", "↵"))
+	out.WriteString("\x1b[K")
+	if back := displayWidth(e.line[e.cursor:end]); back > 0 {
+		out.WriteString(fmt.Sprintf("\x1b[%dD", back))
+	}
+
+	e.out.Write(out.Bytes())
+}
+
+// columns is the width of the terminal the line is drawn on
+func (e *editor) columns() int {
+	if e.term != nil {
+		if width := e.term.width(); width > 0 {
+			return width
+		}
+	}
+	return defaultWidth
+}
@@ -0,0 +1,301 @@
+package repl
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"monkey/object"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEditorKeys(t *testing.T) {
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{"let x = 1;\r", "let x = 1;"},
+		{"let x = 1;\n", "let x = 1;"},
+		{"lte\x7f\x7fet\r", "let"},
+		{"1 + 3\x1b[D\x1b[D\x1b[D\x1b[3~-\r", "1 - 3"},
+		{"bc\x01a\x05d\r", "abcd"},
+		{"ac\x02b\x06d\r", "abcd"},
+		{"let x = 1\x15y\r", "y"},
+		{"let x = 1\x01\x0b\r", ""},
+		{"let foo = bar\x17\x17baz\r", "let foo baz"},
+		{"ab\x1b[1;5Dc\r", "abc"},
+		{"a\x1b[Hb\x1b[Fc\r", "bac"},
+		{"abc\x1b[D\x04\r", "ab"},
+		{"é\x1b[D\x1b[Ca\r", "éa"},
+	}
+
+	for _, tt := range tests {
+		e := newTestEditor(tt.keys, nil, nil)
+		line, err := e.readLine(PROMPT)
+		if err != nil {
+			t.Errorf("%q - readLine returned error: %s", tt.keys, err)
+			continue
+		}
+		if line != tt.expected {
+			t.Errorf("%q - wrong line. expected=%q, got=%q", tt.keys, tt.expected, line)
+		}
+	}
+}
+
+func TestEditorControlKeys(t *testing.T) {
+	tests := []struct {
+		keys     string
+		expected error
+	}{
+		{"", io.EOF},
+		{"\x04", io.EOF},
+		{"let x\x03", errInterrupted},
+	}
+
+	for _, tt := range tests {
+		e := newTestEditor(tt.keys, nil, nil)
+		if _, err := e.readLine(PROMPT); err != tt.expected {
+			t.Errorf("%q - wrong error. expected=%v, got=%v", tt.keys, tt.expected, err)
+		}
+	}
+}
+
+func TestEditorHistory(t *testing.T) {
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{"\x1b[A\r", "third"},
+		{"\x1b[A\x1b[A\r", "second"},
+		{"\x1b[A\x1b[A\x1b[A\x1b[A\r", "first"},
+		{"\x10\x10\x0e\r", "third"},
+		{"dra\x1b[A\x1b[Bft\r", "draft"},
+		{"\x1b[A!\r", "third!"},
+	}
+
+	for _, tt := range tests {
+		h := &history{entries: []string{"first", "second", "third"}, limit: HISTORY_LIMIT}
+		e := newTestEditor(tt.keys, h, nil)
+		line, err := e.readLine(PROMPT)
+		if err != nil {
+			t.Errorf("%q - readLine returned error: %s", tt.keys, err)
+			continue
+		}
+		if line != tt.expected {
+			t.Errorf("%q - wrong line. expected=%q, got=%q", tt.keys, tt.expected, line)
+		}
+	}
+}
+
+func TestEditorCompletion(t *testing.T) {
+	complete := func(prefix string) []string {
+		matches := []string{}
+		for _, name := range []string{"first", "fn", "foobar", "foobaz", "let"} {
+			if strings.HasPrefix(name, prefix) {
+				matches = append(matches, name)
+			}
+		}
+		return matches
+	}
+
+	tests := []struct {
+		keys           string
+		expected       string
+		expectedOutput string
+	}{
+		{"l\t x\r", "let x", ""},
+		{"(fi\t\r", "(first", ""},
+		{"foo\t\r", "fooba", ""},
+		{"fooba\t\r", "fooba", "\r\nfoobar  foobaz\r\n"},
+		{"x\t\r", "x", "\a"},
+		{"fi\x01\t\r", "fi", ""},
+	}
+
+	for _, tt := range tests {
+		e := newTestEditor(tt.keys, nil, complete)
+		line, err := e.readLine(PROMPT)
+		if err != nil {
+			t.Errorf("%q - readLine returned error: %s", tt.keys, err)
+			continue
+		}
+		if line != tt.expected {
+			t.Errorf("%q - wrong line. expected=%q, got=%q", tt.keys, tt.expected, line)
+		}
+		output := e.out.(*bytes.Buffer).String()
+		if tt.expectedOutput != "" && !strings.Contains(output, tt.expectedOutput) {
+			t.Errorf("%q - output does not contain %q. got=%q", tt.keys, tt.expectedOutput, output)
+		}
+	}
+}
+
+func TestEditorRefresh(t *testing.T) {
+	long := strings.Repeat("a", 100)
+	wide := strings.Repeat("世", 40)
+
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{"世界\x1b[D", ">> 世界\x1b[K\x1b[2D"},
+		{"e\u0301x\x1b[D", ">> e\u0301x\x1b[K\x1b[1D"},
+		{"😀!\x01", ">> 😀!\x1b[K\x1b[3D"},
+		{long, ">> " + strings.Repeat("a", 76) + "\x1b[K"},
+		{long + "\x01", ">> " + strings.Repeat("a", 76) + "\x1b[K\x1b[76D"},
+		{long + "\x01\x06\x06", ">> " + strings.Repeat("a", 76) + "\x1b[K\x1b[74D"},
+		{wide, ">> " + strings.Repeat("世", 38) + "\x1b[K"},
+		{long + "\x15", ">> \x1b[K"},
+		{long + "\x7f\x7f\x7f", ">> " + strings.Repeat("a", 76) + "\x1b[K"},
+	}
+
+	for _, tt := range tests {
+		e := newTestEditor(tt.keys+"\r", nil, nil)
+		if _, err := e.readLine(PROMPT); err != nil {
+			t.Errorf("%q - readLine returned error: %s", tt.keys, err)
+			continue
+		}
+		output := strings.TrimSuffix(e.out.(*bytes.Buffer).String(), "\r\n")
+		frame := output[strings.LastIndex(output, "\r")+1:]
+		if frame != tt.expected {
+			t.Errorf("%q - wrong line drawn. expected=%q, got=%q", tt.keys, tt.expected, frame)
+		}
+	}
+}
+
+func TestRuneWidth(t *testing.T) {
+	tests := []struct {
+		input    rune
+		expected int
+	}{
+		{'a', 1},
+		{'é', 1},
+		{'\u0301', 0},
+		{'\u200d', 0},
+		{'世', 2},
+		{'한', 2},
+		{'Ａ', 2},
+		{'😀', 2},
+		{'↵', 1},
+	}
+
+	for _, tt := range tests {
+		if got := runeWidth(tt.input); got != tt.expected {
+			t.Errorf("runeWidth(%q) wrong. expected=%d, got=%d", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestSessionCompletions(t *testing.T) {
+	env := object.NewEnvironment()
+	for _, name := range []string{"fib", "rate", "fn_helper"} {
+		env.Set(name, &object.Integer{Value: 1})
+	}
+
+	s := &Session{out: &bytes.Buffer{}, env: env}
+	tests := []struct {
+		prefix   string
+		expected []string
+	}{
+		{"f", []string{"false", "fib", "first", "fn", "fn_helper"}},
+		{"re", []string{"rest", "return"}},
+		{"Tr", []string{"True"}},
+		{"z", []string{}},
+	}
+
+	for _, tt := range tests {
+		got := s.completions(tt.prefix)
+		if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("completions(%q) wrong. expected=%q, got=%q", tt.prefix, tt.expected, got)
+		}
+	}
+}
+
+func TestHistoryPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), HISTORY_FILE)
+
+	h := loadHistory(path, 3)
+	for _, line := range []string{"one", "", "two", "two", "three", "four"} {
+		h.add(line)
+	}
+	if strings.Join(h.entries, ",") != "two,three,four" {
+		t.Errorf("wrong entries in memory. got=%q", h.entries)
+	}
+
+	reloaded := loadHistory(path, 3)
+	if strings.Join(reloaded.entries, ",") != "two,three,four" {
+		t.Errorf("wrong entries after reload. got=%q", reloaded.entries)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("history file not readable: %s", err)
+	}
+	if string(content) != "two\nthree\nfour\n" {
+		t.Errorf("history file not trimmed. got=%q", content)
+	}
+
+	e := newTestEditor("\x1b[A\r", reloaded, nil)
+	if line, _ := e.readLine(PROMPT); line != "four" {
+		t.Errorf("wrong line recalled. expected=%q, got=%q", "four", line)
+	}
+}
+
+func TestMultiLineHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), HISTORY_FILE)
+	e := newTestEditor("let f = fn() {\r1\r}\r:env\r\x1b[A\x1b[A\rf()\r", loadHistory(path, HISTORY_LIMIT), nil)
+
+	var out bytes.Buffer
+	s := newSession(&out)
+	e.out = &out
+	s.loop(e)
+
+	expected := []string{"let f = fn() {\n1\n}", ":env", "let f = fn() {\n1\n}", "f()"}
+	if strings.Join(e.history.entries, "|") != strings.Join(expected, "|") {
+		t.Errorf("wrong entries. expected=%q, got=%q", expected, e.history.entries)
+	}
+	if !strings.Contains(out.String(), ">> let f = fn() {↵1↵}\x1b[K") {
+		t.Errorf("multi-line entry not recalled. got=%q", out.String())
+	}
+
+	reloaded := loadHistory(path, HISTORY_LIMIT)
+	if strings.Join(reloaded.entries, "|") != strings.Join(expected, "|") {
+		t.Errorf("wrong entries after reload. got=%q", reloaded.entries)
+	}
+}
+
+func TestHistoryEscaping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), HISTORY_FILE)
+	entries := []string{"1 +\n2", `puts("a\nb")`, `"\\"`, "\\"}
+
+	h := loadHistory(path, HISTORY_LIMIT)
+	for _, entry := range entries {
+		h.add(entry)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("history file not readable: %s", err)
+	}
+	if lines := strings.Count(string(content), "\n"); lines != len(entries) {
+		t.Errorf("wrong number of lines in history file. expected=%d, got=%d", len(entries), lines)
+	}
+
+	reloaded := loadHistory(path, HISTORY_LIMIT)
+	if strings.Join(reloaded.entries, "|") != strings.Join(entries, "|") {
+		t.Errorf("entries changed by reload. expected=%q, got=%q", entries, reloaded.entries)
+	}
+}
+
+// Editor Building Internal Functions
+
+func newTestEditor(keys string, h *history, complete func(string) []string) *editor {
+	if h == nil {
+		h = loadHistory("", HISTORY_LIMIT)
+	}
+	return &editor{
+		in:       bufio.NewReader(strings.NewReader(keys)),
+		out:      &bytes.Buffer{},
+		history:  h,
+		complete: complete,
+	}
+}
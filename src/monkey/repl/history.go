@@ -0,0 +1,117 @@
+package repl
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	HISTORY_FILE  = ".monkey_history"
+	HISTORY_LIMIT = 1000
+)
+
+// history keeps the inputs entered in the editor, oldest first. When path
+// is set, every new entry is also appended to that file, one per line, with
+// the newlines of a multi-line entry escaped.
+type history struct {
+	entries []string
+	path    string
+	limit   int
+}
+
+// historyPath is the history file in the user's home directory, or empty
+// when there is no home directory to keep it in
+func historyPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, HISTORY_FILE)
+}
+
+// loadHistory reads the last limit entries of the file at path. A missing
+// or unreadable file starts an empty history.
+func loadHistory(path string, limit int) *history {
+	h := &history{entries: []string{}, path: path, limit: limit}
+	if path == "" {
+		return h
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return h
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		h.entries = append(h.entries, unescapeEntry(scanner.Text()))
+	}
+	if len(h.entries) > limit {
+		h.entries = h.entries[len(h.entries)-limit:]
+		h.rewrite()
+	}
+	return h
+}
+
+// add records an entry, skipping blank ones and repeats of the previous one
+func (h *history) add(entry string) {
+	if strings.TrimSpace(entry) == "" {
+		return
+	}
+	if len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry {
+		return
+	}
+
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > h.limit {
+		h.entries = h.entries[len(h.entries)-h.limit:]
+	}
+
+	if h.path == "" {
+		return
+	}
+	file, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	file.WriteString(escapeEntry(entry) + "\n")
+}
+
+// rewrite replaces the file with the entries kept in memory
+func (h *history) rewrite() {
+	lines := make([]string, len(h.entries))
+	for i, entry := range h.entries {
+		lines[i] = escapeEntry(entry)
+	}
+	content := strings.Join(lines, "\n") + "\n"
+	os.WriteFile(h.path, []byte(content), 0600)
+}
+
+// Utilities
+
+// escapeEntry writes an entry on a single line: newlines become \n and
+// backslashes are doubled so that they survive unescapeEntry
+func escapeEntry(entry string) string {
+	return strings.NewReplacer("\\", "\\\\", "\n", "\\n").Replace(entry)
+}
+
+// unescapeEntry reverses escapeEntry; a backslash before any other
+// character is kept as it is
+func unescapeEntry(line string) string {
+	var entry strings.Builder
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' && i+1 < len(line) && (line[i+1] == 'n' || line[i+1] == '\\') {
+			i++
+			if line[i] == 'n' {
+				entry.WriteByte('\n')
+				continue
+			}
+		}
+		entry.WriteByte(line[i])
+	}
+	return entry.String()
+}
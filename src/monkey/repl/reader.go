@@ -0,0 +1,80 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+)
+
+// errInterrupted is returned when Ctrl-C cancels the line being read
+var errInterrupted = errors.New("interrupted")
+
+// lineReader prompts for and reads one line of input at a time. It returns
+// io.EOF once the input is exhausted.
+type lineReader interface {
+	readLine(prompt string) (string, error)
+}
+
+// historyRecorder is implemented by readers that keep a history. The loop
+// passes it each input once complete, with continuation lines joined.
+type historyRecorder interface {
+	addHistory(entry string)
+}
+
+// plainReader reads lines from any io.Reader. Lines are scanned in the
+// background so that an interrupt can cancel a read that is blocked; the
+// scanner stops once done is closed.
+type plainReader struct {
+	out    io.Writer
+	lines  chan string
+	done   <-chan struct{}
+	listen func() (<-chan os.Signal, func()) // Catches interrupts until the returned func is called
+}
+
+func newPlainReader(in io.Reader, out io.Writer, done <-chan struct{}) *plainReader {
+	r := &plainReader{out: out, lines: make(chan string), done: done, listen: listenForInterrupts}
+	go r.scan(in)
+	return r
+}
+
+// listenForInterrupts catches SIGINT only while a read is in progress, so
+// that Ctrl-C still stops a runaway evaluation. Each read gets a new
+// channel, which drops any interrupt left over from before the prompt.
+func listenForInterrupts() (<-chan os.Signal, func()) {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	return interrupts, func() { signal.Stop(interrupts) }
+}
+
+func (r *plainReader) scan(in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		select {
+		case r.lines <- scanner.Text():
+		case <-r.done:
+			return
+		}
+	}
+	close(r.lines)
+}
+
+func (r *plainReader) readLine(prompt string) (string, error) {
+	interrupts, stop := r.listen()
+	defer stop()
+
+	fmt.Fprint(r.out, prompt)
+	select {
+	case line, ok := <-r.lines:
+		if !ok {
+			return "", io.EOF
+		}
+		return line, nil
+	case <-interrupts:
+		// The terminal echoed ^C without ending the line
+		fmt.Fprintln(r.out)
+		return "", errInterrupted
+	}
+}
@@ -4,26 +4,227 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"monkey/ast"
+	"monkey/evaluator"
 	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
 	"monkey/token"
+	"os"
+	"sort"
+	"strings"
 )
 
-const PROMPT = ">> "
+const (
+	PROMPT              = ">> "
+	CONTINUATION_PROMPT = ".. "
+)
+
+// Mode selects what the REPL does with each line of input
+
+type Mode int
+
+const (
+	EvalMode   Mode = iota // Parse and evaluate, printing the result
+	TokensMode             // Print the tokens the lexer produces
+	AstMode                // Print the parsed program and its tree
+)
+
+// Session is the state kept between the lines of one REPL run. Commands
+// receive it to inspect and change that state.
+type Session struct {
+	out      io.Writer
+	env      *object.Environment
+	mode     Mode
+	accepted []string            // Top-level statements run so far, for :save
+	commands map[string]*Command // Registered when the session started
+}
+
+func newSession(out io.Writer) *Session {
+	return &Session{
+		out:      out,
+		env:      object.NewEnvironment(),
+		accepted: []string{},
+		commands: registeredCommands(),
+	}
+}
+
+func (s *Session) Out() io.Writer           { return s.out }
+func (s *Session) Env() *object.Environment { return s.env }
+
+// Reset drops every binding and the statements remembered for :save
+func (s *Session) Reset() {
+	s.env = object.NewEnvironment()
+	s.accepted = []string{}
+}
+
+// Eval parses and evaluates the source in the session's environment. Parser
+// errors are printed and reported by returning false; the result is nil for
+// input, such as a let statement, that produces no value.
+//
+// Each top-level statement that runs is remembered for :save, including one
+// that fails, since it may already have changed the environment. Statements
+// after a failure or a return are neither run nor remembered.
+func (s *Session) Eval(source string) (object.Object, bool) {
+	program, ok := s.parse(source)
+	if !ok {
+		return nil, false
+	}
+
+	var result object.Object
+	for _, statement := range program.Statements {
+		result = s.exec(source, statement)
+
+		switch result := result.(type) {
+		case *object.ReturnValue:
+			return result.Value, true
+		case *object.Error:
+			return result, true
+		}
+	}
+	return result, true
+}
+
+// exec evaluates one top-level statement of the source and remembers its
+// text, terminated so that saved statements cannot run into each other
+func (s *Session) exec(source string, statement ast.Statement) object.Object {
+	s.accepted = append(s.accepted, source[statement.Pos().Offset:statement.End().Offset]+";")
+	return evaluator.Eval(statement, s.env)
+}
 
 func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
+	done := make(chan struct{})
+	defer close(done)
+
+	s := newSession(out)
+	s.loop(s.newReader(in, out, done))
+}
+
+// newReader uses the line editor when the input is an interactive terminal
+// and reads plain lines otherwise. Closing done releases the plain reader.
+func (s *Session) newReader(in io.Reader, out io.Writer, done <-chan struct{}) lineReader {
+	if file, ok := in.(*os.File); ok {
+		if term, ok := openTerminal(file.Fd()); ok {
+			return &editor{
+				in:       bufio.NewReader(file),
+				out:      out,
+				term:     term,
+				history:  loadHistory(historyPath(), HISTORY_LIMIT),
+				complete: s.completions,
+			}
+		}
+	}
+	return newPlainReader(in, out, done)
+}
+
+// loop reads until the input is exhausted. Lines are collected while the
+// input so far is incomplete, and Ctrl-C drops a pending block and shows a
+// fresh prompt.
+func (s *Session) loop(reader lineReader) {
+	pending := []string{}
 
 	for {
-		fmt.Fprint(out, PROMPT)
-		scanned := scanner.Scan()
-		if !scanned {
+		prompt := PROMPT
+		if len(pending) > 0 {
+			prompt = CONTINUATION_PROMPT
+		}
+
+		line, err := reader.readLine(prompt)
+		if err == errInterrupted {
+			pending = pending[:0]
+			continue
+		}
+		if err != nil {
 			return
 		}
 
-		line := scanner.Text()
-		l := lexer.New(line)
-		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
-			fmt.Fprintf(out, "%+v\n", tok)
+		if len(pending) == 0 && strings.HasPrefix(strings.TrimSpace(line), ":") {
+			remember(reader, line)
+			s.runCommand(line)
+			continue
+		}
+
+		pending = append(pending, line)
+		source := strings.Join(pending, "\n")
+		if s.mode != TokensMode && incomplete(source) {
+			continue
 		}
+		pending = pending[:0]
+		remember(reader, source)
+		s.run(source)
+	}
+}
+
+// remember adds a complete input to the reader's history, if it keeps one
+func remember(reader lineReader, input string) {
+	if recorder, ok := reader.(historyRecorder); ok {
+		recorder.addHistory(input)
+	}
+}
+
+func (s *Session) run(source string) {
+	switch s.mode {
+	case TokensMode:
+		s.printTokens(source)
+	case AstMode:
+		if program, ok := s.parse(source); ok {
+			fmt.Fprintln(s.out, program.String())
+			fmt.Fprint(s.out, tree(program))
+		}
+	default:
+		if evaluated, ok := s.Eval(source); ok && evaluated != nil {
+			fmt.Fprintln(s.out, evaluated.Inspect())
+		}
+	}
+}
+
+func (s *Session) printTokens(source string) {
+	l := lexer.New(source)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		fmt.Fprintf(s.out, "%+v\n", tok)
+	}
+}
+
+// completions lists the keywords, builtins and session bindings starting
+// with prefix, sorted and without duplicates
+func (s *Session) completions(prefix string) []string {
+	names := append(token.Keywords(), evaluator.BuiltinNames()...)
+	names = append(names, s.env.Names()...)
+	sort.Strings(names)
+
+	matches := []string{}
+	for i, name := range names {
+		if strings.HasPrefix(name, prefix) && (i == 0 || name != names[i-1]) {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
+
+// incomplete reports whether the source stops in the middle of a construct,
+// such as an unclosed brace, a trailing operator or an unterminated block
+// comment, that more input could still complete. A string cannot continue
+// onto the next line, so an unterminated one is reported straight away.
+func incomplete(source string) bool {
+	p := parser.New(lexer.New(source))
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) == 0 {
+		return false
+	}
+	return errors[0].Actual == token.EOF || errors[0].Code == parser.ErrUnclosedComment
+}
+
+// parse prints any parser errors against the source and reports whether
+// the program can be used
+func (s *Session) parse(source string) (*ast.Program, bool) {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+
+	if errors := p.Errors(); len(errors) != 0 {
+		fmt.Fprintln(s.out, errors.Render(source))
+		return nil, false
 	}
+	return program, true
 }
@@ -0,0 +1,245 @@
+package repl
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStartEvaluates(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"1 + 2\n", []string{"3"}},
+		{"let x = 5;\nx * 2\n", []string{"10"}},
+		{"let add = fn(a, b) { a + b };\nadd(1, 2)\nadd\n", []string{"3", "fn(a, b) { (a + b); }"}},
+		{`{"a": [1, "b"]}` + "\n", []string{`{"a": [1, "b"]}`}},
+		{"1 / 0\n", []string{"ERROR: 1:1: division by zero"}},
+		{"if (false) { 1 }\n", []string{"null"}},
+	}
+
+	for _, tt := range tests {
+		testOutput(t, tt.input, tt.expected)
+	}
+}
+
+func TestStartRendersParserErrors(t *testing.T) {
+	input := "let = 5\nlet y = 1;\ny\n"
+	expected := []string{
+		"1:5: error[E001]: expected next token to be IDENT, got = instead",
+		"let = 5",
+		"    ^",
+		"1",
+	}
+
+	testOutput(t, input, expected)
+}
+
+func TestStartModes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{":tokens\nlet\n", []string{"{Type:LET Literal:let Pos:1:1 End:1:4 Comments:[]}"}},
+		{":ast\n-a + 1\n", []string{
+			"((-a) + 1)",
+			"Program",
+			"  ExpressionStatement 1:1",
+			"    InfixExpression + 1:1",
+			"      PrefixExpression - 1:1",
+			"        Identifier a 1:2",
+			"      IntegerLiteral 1 1:6",
+		}},
+		{":ast\n-(a + 1)\n", []string{
+			"(-(a + 1))",
+			"Program",
+			"  ExpressionStatement 1:1",
+			"    PrefixExpression - 1:1",
+			"      GroupedExpression 1:2",
+			"        InfixExpression + 1:3",
+			"          Identifier a 1:3",
+			"          IntegerLiteral 1 1:7",
+		}},
+		{":ast\nlet x = 1;\n:eval\nx\n", []string{
+			"let x = 1;",
+			"Program",
+			"  LetStatement 1:1",
+			"    Identifier x 1:5",
+			"    IntegerLiteral 1 1:9",
+			"ERROR: 1:1: identifier not found: x",
+		}},
+	}
+
+	for _, tt := range tests {
+		testOutput(t, tt.input, tt.expected)
+	}
+}
+
+func TestStartContinuesIncompleteInput(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let add = fn(a, b) {\n  a + b\n};\nadd(1, 2)\n", []string{"3"}},
+		{"1 +\n2\n", []string{"3"}},
+		{"[1,\n2,\n]\n", []string{"[1, 2]"}},
+		{"if (true) { 1 } else\n{ 2 }\n", []string{"1"}},
+		{"/* note\n*/ 1\n", []string{"1"}},
+		{"\"abc\n1\n", []string{
+			"1:1: error[E008]: unterminated string literal",
+			"\"abc",
+			"^^^^",
+			"1",
+		}},
+		{"let x = (\n)\n", []string{
+			"2:1: error[E002]: no prefix parse function for ) found",
+			")",
+			"^",
+		}},
+		{":tokens\n(\n", []string{"{Type:( Literal:( Pos:1:1 End:1:2 Comments:[]}"}},
+	}
+
+	for _, tt := range tests {
+		testOutput(t, tt.input, tt.expected)
+	}
+}
+
+func TestIncomplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"1 + 2", false},
+		{"1 +", true},
+		{"let f = fn(x) {", true},
+		{`"abc`, false},
+		{"/* note", true},
+		{"1 + /* a /* b */", true},
+		{"/* note\n*/ 1", false},
+		{"\"abc\n1", false},
+		{"let = 1", false},
+	}
+
+	for _, tt := range tests {
+		if got := incomplete(tt.input); got != tt.expected {
+			t.Errorf("incomplete(%q) wrong. expected=%t, got=%t", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestInterruptDropsPendingInput(t *testing.T) {
+	reader := &scriptedReader{steps: []scriptedLine{
+		{line: "let f = fn() {"},
+		{err: errInterrupted},
+		{line: "let x = 1;"},
+		{line: "x"},
+		{err: errInterrupted},
+		{line: "2"},
+	}}
+
+	var out bytes.Buffer
+	s := newSession(&out)
+	s.loop(reader)
+
+	expectedPrompts := []string{PROMPT, CONTINUATION_PROMPT, PROMPT, PROMPT, PROMPT, PROMPT}
+	if strings.Join(reader.prompts, "|") != strings.Join(expectedPrompts, "|") {
+		t.Errorf("wrong prompts. expected=%q, got=%q", expectedPrompts, reader.prompts)
+	}
+	if out.String() != "1\n2\n" {
+		t.Errorf("wrong output. expected=%q, got=%q", "1\n2\n", out.String())
+	}
+}
+
+func TestPlainReaderInterrupt(t *testing.T) {
+	in, writer := io.Pipe()
+	defer writer.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	var out bytes.Buffer
+	reader := newPlainReader(in, &out, done)
+	stopped := false
+	reader.listen = func() (<-chan os.Signal, func()) {
+		interrupts := make(chan os.Signal, 1)
+		interrupts <- os.Interrupt
+		return interrupts, func() { stopped = true }
+	}
+
+	if _, err := reader.readLine(PROMPT); err != errInterrupted {
+		t.Errorf("wrong error. expected=%v, got=%v", errInterrupted, err)
+	}
+	if !stopped {
+		t.Errorf("interrupts still caught after the read returned")
+	}
+	if out.String() != PROMPT+"\n" {
+		t.Errorf("wrong output. expected=%q, got=%q", PROMPT+"\n", out.String())
+	}
+}
+
+func TestPlainReaderStopsWhenDone(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	done := make(chan struct{})
+	reader := newPlainReader(strings.NewReader("1\n2\n3\n"), &bytes.Buffer{}, done)
+	if line, err := reader.readLine(PROMPT); err != nil || line != "1" {
+		t.Fatalf("wrong first line. got=%q (%v)", line, err)
+	}
+	close(done)
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("scanner goroutine still running after done was closed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// scriptedReader replays lines and errors in order, recording the prompts
+type scriptedReader struct {
+	steps   []scriptedLine
+	prompts []string
+}
+
+type scriptedLine struct {
+	line string
+	err  error
+}
+
+func (r *scriptedReader) readLine(prompt string) (string, error) {
+	if len(r.steps) == 0 {
+		return "", io.EOF
+	}
+	r.prompts = append(r.prompts, prompt)
+	step := r.steps[0]
+	r.steps = r.steps[1:]
+	return step.line, step.err
+}
+
+// Output Checking Internal Functions
+
+func testOutput(t *testing.T, input string, expected []string) bool {
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+
+	output := strings.ReplaceAll(out.String(), PROMPT, "")
+	output = strings.ReplaceAll(output, CONTINUATION_PROMPT, "")
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	if len(lines) != len(expected) {
+		t.Errorf("%q - wrong number of output lines. expected=%q, got=%q", input, expected, lines)
+		return false
+	}
+	for i, line := range lines {
+		if line != expected[i] {
+			t.Errorf("%q - output line %d wrong. expected=%q, got=%q", input, i, expected[i], line)
+			return false
+		}
+	}
+	return true
+}
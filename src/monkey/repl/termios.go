@@ -0,0 +1,60 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package repl
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// terminal switches a terminal between its original mode and raw mode,
+// in which every key press is read as it is typed and nothing is echoed
+type terminal struct {
+	fd       uintptr
+	original syscall.Termios
+}
+
+// openTerminal reports false when the file descriptor is not a terminal
+func openTerminal(fd uintptr) (*terminal, bool) {
+	t := &terminal{fd: fd}
+	if err := ioctlTermios(fd, ioctlGetTermios, &t.original); err != nil {
+		return nil, false
+	}
+	return t, true
+}
+
+// makeRaw applies the same settings as cfmakeraw(3)
+func (t *terminal) makeRaw() error {
+	raw := t.original
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP |
+		syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Oflag &^= syscall.OPOST
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	return ioctlTermios(t.fd, ioctlSetTermios, &raw)
+}
+
+func (t *terminal) restore() error {
+	return ioctlTermios(t.fd, ioctlSetTermios, &t.original)
+}
+
+// width is the number of columns in the terminal, or 0 when it is unknown
+func (t *terminal) width() int {
+	var size struct{ rows, columns, xpixels, ypixels uint16 }
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, t.fd, syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&size)))
+	if errno != 0 {
+		return 0
+	}
+	return int(size.columns)
+}
+
+func ioctlTermios(fd uintptr, request uintptr, termios *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(unsafe.Pointer(termios)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package repl
+
+import "errors"
+
+// terminal is unsupported on this platform, so the REPL always reads
+// input in plain mode
+type terminal struct{}
+
+func openTerminal(fd uintptr) (*terminal, bool) {
+	return nil, false
+}
+
+func (t *terminal) makeRaw() error {
+	return errors.New("raw mode is not supported on this platform")
+}
+
+func (t *terminal) restore() error {
+	return nil
+}
+
+func (t *terminal) width() int {
+	return 0
+}
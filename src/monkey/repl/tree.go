@@ -0,0 +1,109 @@
+package repl
+
+import (
+	"bytes"
+	"fmt"
+	"monkey/ast"
+	"strings"
+)
+
+// tree renders a node and its children one per line, indented by depth,
+// each labelled with its type, its notable value and where it starts
+func tree(node ast.Node) string {
+	var out bytes.Buffer
+	writeTree(&out, node, 0)
+	return out.String()
+}
+
+func writeTree(out *bytes.Buffer, node ast.Node, depth int) {
+	detail, children := describe(node)
+
+	out.WriteString(strings.Repeat("  ", depth))
+	out.WriteString(strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast."))
+	if detail != "" {
+		out.WriteString(" " + detail)
+	}
+	if _, ok := node.(*ast.Program); !ok {
+		out.WriteString(" " + node.Pos().String())
+	}
+	out.WriteString("\n")
+
+	for _, child := range children {
+		writeTree(out, child, depth+1)
+	}
+}
+
+// describe returns the label detail and the children of a node, leaving out
+// optional children that are missing
+func describe(node ast.Node) (string, []ast.Node) {
+	children := []ast.Node{}
+	add := func(nodes ...ast.Node) {
+		for _, n := range nodes {
+			if n != nil {
+				children = append(children, n)
+			}
+		}
+	}
+
+	switch node := node.(type) {
+	case *ast.Program:
+		for _, stmt := range node.Statements {
+			add(stmt)
+		}
+	case *ast.LetStatement:
+		add(node.Name, node.Value)
+	case *ast.AssignStatement:
+		add(node.Name, node.Value)
+		return node.Operator, children
+	case *ast.ReturnStatement:
+		add(node.ReturnValue)
+	case *ast.ExpressionStatement:
+		add(node.Expression)
+	case *ast.BlockStatement:
+		for _, stmt := range node.Statements {
+			add(stmt)
+		}
+	case *ast.Identifier:
+		return node.Value, children
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.Boolean:
+		return node.String(), children
+	case *ast.IfExpression:
+		add(node.Condition, node.Consequence)
+		if node.Alternative != nil {
+			add(node.Alternative)
+		}
+	case *ast.FunctionLiteral:
+		for _, param := range node.Parameters {
+			add(param)
+		}
+		add(node.Body)
+	case *ast.CallExpression:
+		add(node.Function)
+		for _, arg := range node.Arguments {
+			add(arg)
+		}
+	case *ast.ArrayLiteral:
+		for _, element := range node.Elements {
+			add(element)
+		}
+	case *ast.HashLiteral:
+		for _, pair := range node.Pairs {
+			add(pair.Key, pair.Value)
+		}
+	case *ast.IndexExpression:
+		add(node.Left, node.Index)
+	case *ast.GroupedExpression:
+		add(node.Expression)
+	case *ast.PrefixExpression:
+		add(node.Right)
+		return node.Operator, children
+	case *ast.InfixExpression:
+		add(node.Left, node.Right)
+		return node.Operator, children
+	case *ast.LogicalExpression:
+		add(node.Left, node.Right)
+		return node.Operator, children
+	}
+
+	return "", children
+}
@@ -1,10 +1,30 @@
 package token
 
+import (
+	"fmt"
+	"sort"
+)
+
 type TokenType string
 
+// Position locates a character in the source; Line and Column start at 1
+type Position struct {
+	Offset int // Byte offset from the start of the input
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Position // First character of the token
+	End     Position // Character right after the token
+
+	Comments []Token // COMMENT tokens right before this one, when the lexer keeps them
 }
 
 const (
@@ -12,8 +32,13 @@ const (
 	EOF     = "EOF"
 
 	// Identifier + Literal
-	IDENT = "IDENT"
-	INT   = "INT"
+	IDENT  = "IDENT"
+	INT    = "INT"
+	FLOAT  = "FLOAT"
+	STRING = "STRING"
+
+	// Trivia
+	COMMENT = "COMMENT"
 
 	// Operator
 	ASSIGN   = "="
@@ -31,6 +56,10 @@ const (
 	EQ  = "=="
 	NEQ = "!="
 
+	// Logical
+	AND = "&&"
+	OR  = "||"
+
 	// Operate and Assign
 	ADDASSIGN   = "+="
 	MINUSASSIGN = "-="
@@ -40,12 +69,15 @@ const (
 	// Separator
 	COMMA     = ","
 	SEMICOLON = ";"
+	COLON     = ":"
 
 	// Parantheseses
-	LPAREN = "("
-	RPAREN = ")"
-	LBRACE = "{"
-	RBRACE = "}"
+	LPAREN   = "("
+	RPAREN   = ")"
+	LBRACE   = "{"
+	RBRACE   = "}"
+	LBRACKET = "["
+	RBRACKET = "]"
 
 	// Keywords
 	FUNCTION = "FUNCTION"
@@ -75,3 +107,13 @@ func LookupIdent(ident string) TokenType {
 	}
 	return IDENT
 }
+
+// Keywords lists the sorted keyword spellings, including the boolean aliases
+func Keywords() []string {
+	words := make([]string, 0, len(keywords))
+	for word := range keywords {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
+}